@@ -3,11 +3,20 @@ package main
 import (
 	"Arise-test/configs"
 	"Arise-test/internal/handler"
+	"Arise-test/internal/middleware"
+	"Arise-test/internal/migrations"
 	"Arise-test/internal/model"
 	"Arise-test/internal/repository"
 	"Arise-test/internal/routes"
 	"Arise-test/internal/service"
+	"Arise-test/internal/storage"
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/postgres"
@@ -18,6 +27,14 @@ func main() {
 	// Load configuration
 	config := configs.LoadConfig()
 
+	// `migrate up|down|status|create` manages the schema instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(config, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	// Set Gin mode
 	gin.SetMode(config.Server.GinMode)
 
@@ -27,32 +44,91 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Auto migrate database
-	err = db.AutoMigrate(&model.User{}, &model.Task{}, &model.Category{})
-	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	// Apply pending migrations
+	if config.Database.AutoMigrate {
+		if err := migrateUp(db); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
 	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	boardRepo := repository.NewBoardRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	taskDependencyRepo := repository.NewTaskDependencyRepository(db)
+	taskStatusChangeRepo := repository.NewTaskStatusChangeRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	taskCollaboratorRepo := repository.NewTaskCollaboratorRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	txManager := repository.NewTxManager(db)
+
+	// Initialize attachment storage
+	fileStorage, err := storage.New(config.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
 
 	// Initialize services
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, txManager)
+	taskService := service.NewTaskService(taskRepo, categoryRepo, projectRepo, boardRepo, taskDependencyRepo, taskStatusChangeRepo, taskCollaboratorRepo, txManager, config.Tasks)
+	categoryService := service.NewCategoryService(categoryRepo, txManager)
+	projectService := service.NewProjectService(projectRepo)
+	boardService := service.NewBoardService(boardRepo)
+	trashService := service.NewTrashService(trashRepo, fileStorage, config.Trash)
+	authService := service.NewAuthService(userService, refreshTokenRepo, config.Security)
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	commentService := service.NewCommentService(commentRepo, taskRepo, workspaceRepo)
+	attachmentService := service.NewAttachmentService(attachmentRepo, taskRepo, fileStorage, config.Attachments)
+	tagService := service.NewTagService(tagRepo, taskRepo)
+	collaboratorService := service.NewCollaboratorService(taskCollaboratorRepo, taskRepo, userRepo, workspaceRepo)
+	workspaceService := service.NewWorkspaceService(workspaceRepo, invitationRepo, userRepo, userService, txManager, config.Workspaces)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, config.Idempotency)
 
 	// Initialize handlers
 	userHandler := handler.NewUserHandler(userService)
 	taskHandler := handler.NewTaskHandler(taskService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
+	projectHandler := handler.NewProjectHandler(projectService)
+	boardHandler := handler.NewBoardHandler(boardService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	authHandler := handler.NewAuthHandler(authService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	commentHandler := handler.NewCommentHandler(commentService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, config.Attachments.MaxSize)
+	tagHandler := handler.NewTagHandler(tagService)
+	collaboratorHandler := handler.NewCollaboratorHandler(collaboratorService)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
 
 	// Initialize Gin router
 	router := gin.Default()
 
 	// Setup routes
-	routes.SetupRoutes(router, userHandler, taskHandler, categoryHandler)
+	requirePermission := func(permission model.Permission) gin.HandlerFunc {
+		return middleware.RequirePermission(roleService, permission)
+	}
+	routes.SetupRoutes(router, userHandler, taskHandler, categoryHandler,
+		authHandler, roleHandler, commentHandler, attachmentHandler, tagHandler, collaboratorHandler, workspaceHandler,
+		projectHandler, boardHandler, trashHandler,
+		middleware.AuthMiddleware(authService), middleware.WorkspaceMiddleware(workspaceService),
+		middleware.RequireIfMatch(config.Server.RequireIfMatch), middleware.Idempotency(idempotencyService),
+		middleware.QueryTimeout(config.Database.QueryTimeout), requirePermission)
+
+	// Purge expired trash and idempotency keys in the background
+	if config.Trash.Retention > 0 && config.Trash.PurgeInterval > 0 {
+		go purgePeriodically("items from the trash", config.Trash.PurgeInterval, trashService.PurgeExpired)
+	}
+	if config.Idempotency.PurgeInterval > 0 {
+		go purgePeriodically("idempotency keys", config.Idempotency.PurgeInterval, idempotencyService.PurgeExpired)
+	}
 
 	// Start server
 	log.Printf("Starting server on port %s", config.Server.Port)
@@ -61,6 +137,23 @@ func main() {
 	}
 }
 
+// purgePeriodically runs purge right away and then every interval, logging
+// how many expired entries of the given kind it removed
+func purgePeriodically(what string, interval time.Duration, purge func(ctx context.Context) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := purge(context.Background())
+		if err != nil {
+			log.Printf("Failed to purge %s: %v", what, err)
+		} else if purged > 0 {
+			log.Printf("Purged %d expired %s", purged, what)
+		}
+		<-ticker.C
+	}
+}
+
 func initDB(config *configs.Config) (*gorm.DB, error) {
 	dsn := config.GetDatabaseDSN()
 
@@ -74,3 +167,88 @@ func initDB(config *configs.Config) (*gorm.DB, error) {
 
 	return db, nil
 }
+
+func migrateUp(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	migrator, err := migrations.NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// runMigrate implements the migrate subcommand
+func runMigrate(config *configs.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down [steps]|status|create <name>")
+	}
+
+	// create only writes files and does not need a database
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return errors.New("usage: migrate create <name>")
+		}
+		paths, err := migrations.Create("internal/migrations/sql", args[1])
+		for _, path := range paths {
+			log.Printf("Created %s", path)
+		}
+		return err
+	}
+
+	db, err := initDB(config)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	migrator, err := migrations.NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrateUp(db)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			log.Printf("Reverted migration %04d_%s", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
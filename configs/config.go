@@ -1,36 +1,119 @@
 package configs
 
 import (
+	"Arise-test/internal/model"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Security SecurityConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Security    SecurityConfig
+	Tasks       TaskConfig
+	Storage     StorageConfig
+	Attachments AttachmentConfig
+	Workspaces  WorkspaceConfig
+	Trash       TrashConfig
+	Idempotency IdempotencyConfig
 }
 
 type ServerConfig struct {
 	Port    string
 	GinMode string
+	// RequireIfMatch rejects updates and deletes of users, tasks and
+	// categories that do not send If-Match
+	RequireIfMatch bool
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
+	Host        string
+	Port        string
+	User        string
+	Password    string
+	Name        string
+	AutoMigrate bool
+	// QueryTimeout bounds the database queries of a single request; zero
+	// disables it
+	QueryTimeout time.Duration
 }
 
 type SecurityConfig struct {
-	JWTSecret string
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
 }
 
+// TaskConfig holds task workflow settings
+type TaskConfig struct {
+	// StatusTransitions maps each status to the statuses it may change to
+	StatusTransitions map[model.TaskStatus][]model.TaskStatus
+}
+
+// StorageConfig selects where attachment contents are stored
+type StorageConfig struct {
+	// Backend is "local" or "s3"
+	Backend  string
+	LocalDir string
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
+}
+
+// AttachmentConfig limits the files that can be attached to tasks
+type AttachmentConfig struct {
+	// MaxSize is the largest accepted file, in bytes
+	MaxSize int64
+	// AllowedTypes lists the accepted MIME types; "image/*" accepts any
+	// subtype and an empty list accepts everything
+	AllowedTypes []string
+}
+
+// WorkspaceConfig holds team workspace settings
+type WorkspaceConfig struct {
+	// InvitationTTL is how long an invitation can be accepted
+	InvitationTTL time.Duration
+}
+
+// TrashConfig controls how long deleted tasks and categories are kept
+type TrashConfig struct {
+	// Retention is how long deleted items stay in the trash before they are
+	// purged; zero keeps them until they are purged by hand
+	Retention time.Duration
+	// PurgeInterval is how often expired items are purged
+	PurgeInterval time.Duration
+}
+
+// IdempotencyConfig controls how long responses to requests with an
+// Idempotency-Key header are kept for replay
+type IdempotencyConfig struct {
+	// TTL is how long a key can be retried
+	TTL time.Duration
+	// PurgeInterval is how often expired keys are deleted
+	PurgeInterval time.Duration
+}
+
+// DefaultAllowedAttachmentTypes is used unless ATTACHMENT_ALLOWED_TYPES
+// overrides it
+const DefaultAllowedAttachmentTypes = "image/*,text/plain,text/csv,application/pdf,application/zip"
+
+// DefaultStatusTransitions is the transition graph used unless
+// TASK_STATUS_TRANSITIONS overrides it
+const DefaultStatusTransitions = "pending=in_progress,completed,cancelled;" +
+	"in_progress=pending,completed,cancelled;" +
+	"completed=pending,in_progress;" +
+	"cancelled=pending"
+
 var AppConfig *Config
 
 // LoadConfig loads configuration from environment variables
@@ -42,18 +125,51 @@ func LoadConfig() *Config {
 
 	config := &Config{
 		Server: ServerConfig{
-			Port:    getEnv("PORT", "8080"),
-			GinMode: getEnv("GIN_MODE", "debug"),
+			Port:           getEnv("PORT", "8080"),
+			GinMode:        getEnv("GIN_MODE", "debug"),
+			RequireIfMatch: getEnvAsBool("REQUIRE_IF_MATCH", false),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "172.19.18.36"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			Name:     getEnv("DB_NAME", "taskmanager"),
+			Host:         getEnv("DB_HOST", "172.19.18.36"),
+			Port:         getEnv("DB_PORT", "5432"),
+			User:         getEnv("DB_USER", "postgres"),
+			Password:     getEnv("DB_PASSWORD", "password"),
+			Name:         getEnv("DB_NAME", "taskmanager"),
+			AutoMigrate:  getEnvAsBool("DB_AUTO_MIGRATE", true),
+			QueryTimeout: time.Duration(getEnvAsInt("DB_QUERY_TIMEOUT_SECONDS", 30)) * time.Second,
 		},
 		Security: SecurityConfig{
-			JWTSecret: getEnv("JWT_SECRET", "your-development-secret-key"),
+			JWTSecret:       getEnv("JWT_SECRET", "your-development-secret-key"),
+			AccessTokenTTL:  time.Duration(getEnvAsInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute,
+			RefreshTokenTTL: time.Duration(getEnvAsInt("REFRESH_TOKEN_TTL_HOURS", 168)) * time.Hour,
+		},
+		Tasks: TaskConfig{
+			StatusTransitions: getEnvAsTransitions("TASK_STATUS_TRANSITIONS", DefaultStatusTransitions),
+		},
+		Storage: StorageConfig{
+			Backend:           getEnv("STORAGE_BACKEND", "local"),
+			LocalDir:          getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+			S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+			S3Region:          getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:          getEnv("S3_BUCKET", ""),
+			S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:       getEnvAsBool("S3_PATH_STYLE", true),
+		},
+		Attachments: AttachmentConfig{
+			MaxSize:      int64(getEnvAsInt("ATTACHMENT_MAX_SIZE_MB", 10)) << 20,
+			AllowedTypes: getEnvAsList("ATTACHMENT_ALLOWED_TYPES", DefaultAllowedAttachmentTypes),
+		},
+		Workspaces: WorkspaceConfig{
+			InvitationTTL: time.Duration(getEnvAsInt("WORKSPACE_INVITATION_TTL_HOURS", 168)) * time.Hour,
+		},
+		Trash: TrashConfig{
+			Retention:     time.Duration(getEnvAsInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			PurgeInterval: time.Duration(getEnvAsInt("TRASH_PURGE_INTERVAL_MINUTES", 60)) * time.Minute,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:           time.Duration(getEnvAsInt("IDEMPOTENCY_KEY_TTL_HOURS", 24)) * time.Hour,
+			PurgeInterval: time.Duration(getEnvAsInt("IDEMPOTENCY_PURGE_INTERVAL_MINUTES", 60)) * time.Minute,
 		},
 	}
 
@@ -95,6 +211,60 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvAsList gets a comma-separated environment variable as a list with
+// fallback default value
+func getEnvAsList(key, defaultValue string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvAsTransitions gets environment variable as a status transition graph
+// with fallback default value
+func getEnvAsTransitions(key, defaultValue string) map[model.TaskStatus][]model.TaskStatus {
+	if value := os.Getenv(key); value != "" {
+		transitions, err := ParseStatusTransitions(value)
+		if err == nil {
+			return transitions
+		}
+		log.Printf("Warning: invalid %s, using default: %v", key, err)
+	}
+	transitions, _ := ParseStatusTransitions(defaultValue)
+	return transitions
+}
+
+// ParseStatusTransitions parses a transition graph written as
+// "from=to,to;from=to", e.g. "pending=in_progress;in_progress=completed"
+func ParseStatusTransitions(value string) (map[model.TaskStatus][]model.TaskStatus, error) {
+	transitions := map[model.TaskStatus][]model.TaskStatus{}
+	for _, rule := range strings.Split(value, ";") {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		from, targets, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid transition %q", rule)
+		}
+
+		fromStatus := model.TaskStatus(strings.TrimSpace(from))
+		if !fromStatus.IsValid() {
+			return nil, fmt.Errorf("unknown status %q", fromStatus)
+		}
+		for _, to := range strings.Split(targets, ",") {
+			toStatus := model.TaskStatus(strings.TrimSpace(to))
+			if !toStatus.IsValid() {
+				return nil, fmt.Errorf("unknown status %q", toStatus)
+			}
+			transitions[fromStatus] = append(transitions[fromStatus], toStatus)
+		}
+	}
+	return transitions, nil
+}
+
 // GetDatabaseDSN returns database connection string
 func (c *Config) GetDatabaseDSN() string {
 	return "postgres://" + c.Database.User + ":" + c.Database.Password +
@@ -18,8 +18,8 @@
 --     NOW()
 -- );
 
--- Create indexes for better performance
--- These will be created automatically by GORM, but you can add custom ones here
+-- Tables and indexes are created by the application's migrations
+-- (see internal/migrations/sql), not by this script
 
 -- Enable UUID extension if not already enabled
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
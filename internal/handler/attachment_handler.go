@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"Arise-test/internal/service"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead allows for the form boundaries and headers around an
+// uploaded file when capping the request body
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService service.AttachmentService
+	maxUploadSize     int64
+}
+
+// NewAttachmentHandler creates the handler; request bodies are capped a
+// little above maxUploadSize bytes, or not at all when it is zero
+func NewAttachmentHandler(attachmentService service.AttachmentService, maxUploadSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		maxUploadSize:     maxUploadSize,
+	}
+}
+
+// attachments returns the service limited to the request's workspace
+func (h *AttachmentHandler) attachments(c *gin.Context, userID uuid.UUID) service.AttachmentService {
+	return h.attachmentService.InWorkspace(getMembership(c, userID))
+}
+
+// UploadAttachment attaches the multipart "file" field to a task
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	if h.maxUploadSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrAttachmentTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachments(c, userID).UploadAttachment(c.Request.Context(), userID, taskID, service.AttachmentUpload{
+		FileName:    fileHeader.Filename,
+		Size:        fileHeader.Size,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Content:     file,
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"attachment": attachment})
+}
+
+// GetAttachments lists the attachments of a task, oldest first
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	attachments, err := h.attachments(c, userID).GetAttachments(c.Request.Context(), userID, taskID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+// DownloadAttachment streams the contents of an attachment
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	userID, taskID, attachmentID, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.attachments(c, userID).OpenAttachment(c.Request.Context(), userID, taskID, attachmentID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	// Always download rather than render, so an uploaded file cannot run
+	// in the context of the API's origin
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	c.Header("Content-Type", attachment.ContentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		// The status is already sent, so the client only sees a short body
+		log.Printf("Failed to send attachment %s: %v", attachment.ID, err)
+	}
+}
+
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, taskID, attachmentID, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.attachments(c, userID).DeleteAttachment(c.Request.Context(), userID, taskID, attachmentID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "attachment deleted successfully"})
+}
+
+// attachmentParams reads the authenticated user and the task and attachment
+// IDs of an attachment route, writing the error response itself when one is
+// missing
+func attachmentParams(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, taskID, attachmentID, true
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BoardHandler struct {
+	boardService service.BoardService
+}
+
+func NewBoardHandler(boardService service.BoardService) *BoardHandler {
+	return &BoardHandler{
+		boardService: boardService,
+	}
+}
+
+// boards returns the service limited to the request's workspace
+func (h *BoardHandler) boards(c *gin.Context, userID uuid.UUID) service.BoardService {
+	return h.boardService.InWorkspace(getMembership(c, userID))
+}
+
+type ColumnRequest struct {
+	Name   string            `json:"name" binding:"required"`
+	Status *model.TaskStatus `json:"status"`
+}
+
+type CreateBoardRequest struct {
+	Name    string          `json:"name" binding:"required"`
+	Columns []ColumnRequest `json:"columns"`
+}
+
+type UpdateBoardRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdateColumnRequest struct {
+	Name   string            `json:"name"`
+	Status *model.TaskStatus `json:"status"`
+}
+
+// CreateBoard creates a new board with the given columns
+func (h *BoardHandler) CreateBoard(c *gin.Context) {
+	var req CreateBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	board := &model.Board{
+		Name:   req.Name,
+		UserID: userID,
+	}
+	for _, column := range req.Columns {
+		board.Columns = append(board.Columns, model.BoardColumn{
+			Name:   column.Name,
+			Status: column.Status,
+		})
+	}
+
+	if err := h.boards(c, userID).CreateBoard(c.Request.Context(), board); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"board": board})
+}
+
+// GetBoard retrieves a board with its columns and their tasks
+func (h *BoardHandler) GetBoard(c *gin.Context) {
+	userID, id, ok := boardParams(c)
+	if !ok {
+		return
+	}
+
+	board, err := h.boards(c, userID).GetBoardByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"board": board})
+}
+
+// GetUserBoards retrieves all boards for the authenticated user
+func (h *BoardHandler) GetUserBoards(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	boards, err := h.boards(c, userID).GetBoardsByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"boards": boards})
+}
+
+// UpdateBoard renames a board
+func (h *BoardHandler) UpdateBoard(c *gin.Context) {
+	userID, id, ok := boardParams(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, err := h.boards(c, userID).GetBoardByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	board.Name = req.Name
+	if err := h.boards(c, userID).UpdateBoard(c.Request.Context(), userID, board); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"board": board})
+}
+
+// DeleteBoard deletes a board and its columns
+func (h *BoardHandler) DeleteBoard(c *gin.Context) {
+	userID, id, ok := boardParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.boards(c, userID).DeleteBoard(c.Request.Context(), userID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "board deleted successfully"})
+}
+
+// CreateColumn adds a column at the end of a board
+func (h *BoardHandler) CreateColumn(c *gin.Context) {
+	userID, boardID, ok := boardParams(c)
+	if !ok {
+		return
+	}
+
+	var req ColumnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	column := &model.BoardColumn{
+		BoardID: boardID,
+		Name:    req.Name,
+		Status:  req.Status,
+	}
+
+	if err := h.boards(c, userID).CreateColumn(c.Request.Context(), userID, column); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"column": column})
+}
+
+// UpdateColumn renames a column or changes its status
+func (h *BoardHandler) UpdateColumn(c *gin.Context) {
+	userID, boardID, columnID, ok := columnParams(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateColumnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	column, err := h.boards(c, userID).GetColumn(c.Request.Context(), userID, boardID, columnID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update fields if provided; an empty status unmaps the column
+	if req.Name != "" {
+		column.Name = req.Name
+	}
+	if req.Status != nil {
+		column.Status = req.Status
+		if *req.Status == "" {
+			column.Status = nil
+		}
+	}
+
+	if err := h.boards(c, userID).UpdateColumn(c.Request.Context(), userID, column); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"column": column})
+}
+
+// DeleteColumn deletes a column, taking its tasks off the board
+func (h *BoardHandler) DeleteColumn(c *gin.Context) {
+	userID, boardID, columnID, ok := columnParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.boards(c, userID).DeleteColumn(c.Request.Context(), userID, boardID, columnID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "column deleted successfully"})
+}
+
+// boardParams reads the authenticated user and the board ID of a board
+// route, writing the error response itself when one is missing
+func boardParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, id, true
+}
+
+// columnParams reads the authenticated user and the board and column IDs of
+// a column route, writing the error response itself when one is missing
+func columnParams(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, boardID, ok := boardParams(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	columnID, err := uuid.Parse(c.Param("columnId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid column ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, boardID, columnID, true
+}
@@ -4,7 +4,6 @@ import (
 	"Arise-test/internal/model"
 	"Arise-test/internal/service"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,18 +19,33 @@ func NewCategoryHandler(categoryService service.CategoryService) *CategoryHandle
 	}
 }
 
+// categories returns the service limited to the request's workspace
+func (h *CategoryHandler) categories(c *gin.Context, userID uuid.UUID) service.CategoryService {
+	return h.categoryService.InWorkspace(getMembership(c, userID))
+}
+
 type CreateCategoryRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
 	Color       string `json:"color"`
 }
 
+// UpdateCategoryRequest replaces every writable field of a category; omitted
+// fields are cleared
 type UpdateCategoryRequest struct {
-	Name        string `json:"name"`
+	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
 	Color       string `json:"color"`
 }
 
+// PatchCategoryRequest is a JSON Merge Patch of a category: absent members
+// are left untouched and null clears a field
+type PatchCategoryRequest struct {
+	Name        Optional[string] `json:"name"`
+	Description Optional[string] `json:"description"`
+	Color       Optional[string] `json:"color"`
+}
+
 // CreateCategory creates a new category
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	var req CreateCategoryRequest
@@ -40,16 +54,9 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from context (normally would come from JWT token)
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
-		return
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+	// Get user ID from context (set by auth middleware)
+	userID, ok := getUserID(c)
+	if !ok {
 		return
 	}
 
@@ -60,8 +67,8 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		UserID:      userID,
 	}
 
-	if err := h.categoryService.CreateCategory(category); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.categories(c, userID).CreateCategory(c.Request.Context(), category); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -70,6 +77,11 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 
 // GetCategory retrieves a category by ID
 func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -77,30 +89,24 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.GetCategoryByID(id)
+	category, err := h.categories(c, userID).GetCategoryByID(c.Request.Context(), userID, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"category": category})
+	writeVersioned(c, category.Version, gin.H{"category": category})
 }
 
-// GetUserCategories retrieves all categories for a specific user
+// GetUserCategories retrieves all categories for the authenticated user
 func (h *CategoryHandler) GetUserCategories(c *gin.Context) {
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
-		return
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+	// Get user ID from context
+	userID, ok := getUserID(c)
+	if !ok {
 		return
 	}
 
-	categories, err := h.categoryService.GetCategoriesByUserID(userID)
+	categories, err := h.categories(c, userID).GetCategoriesByUserID(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -109,8 +115,13 @@ func (h *CategoryHandler) GetUserCategories(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"categories": categories})
 }
 
-// UpdateCategory updates a category
+// UpdateCategory replaces a category with the request body
 func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -125,33 +136,81 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 	}
 
 	// Get existing category
-	category, err := h.categoryService.GetCategoryByID(id)
+	category, err := h.categories(c, userID).GetCategoryByID(c.Request.Context(), userID, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	// Update fields if provided
-	if req.Name != "" {
-		category.Name = req.Name
+	if !checkIfMatch(c, category.Version) {
+		return
 	}
-	if req.Description != "" {
-		category.Description = req.Description
+
+	category.Name = req.Name
+	category.Description = req.Description
+	category.Color = req.Color
+
+	if err := h.categories(c, userID).UpdateCategory(c.Request.Context(), userID, category); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
-	if req.Color != "" {
-		category.Color = req.Color
+
+	c.Header("ETag", etag(category.Version))
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// PatchCategory applies a JSON Merge Patch to a category
+func (h *CategoryHandler) PatchCategory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
 	}
 
-	if err := h.categoryService.UpdateCategory(category); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	var req PatchCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categories(c, userID).GetCategoryByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkIfMatch(c, category.Version) {
+		return
+	}
+
+	req.Name.apply(&category.Name)
+	req.Description.apply(&category.Description)
+	req.Color.apply(&category.Color)
+
+	if err := h.categories(c, userID).UpdateCategory(c.Request.Context(), userID, category); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", etag(category.Version))
 	c.JSON(http.StatusOK, gin.H{"category": category})
 }
 
-// DeleteCategory deletes a category
+// DeleteCategory deletes a category. The policy query parameter decides what
+// happens to its tasks: detach (default), reassign to the category given by
+// reassign_to, or delete
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -159,8 +218,24 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := h.categoryService.DeleteCategory(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var targetID *uuid.UUID
+	if raw := c.Query("reassign_to"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reassign_to category ID"})
+			return
+		}
+		targetID = &parsed
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	policy := model.CategoryDeletePolicy(c.Query("policy"))
+	if err := h.categories(c, userID).DeleteCategory(c.Request.Context(), userID, id, version, policy, targetID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -169,26 +244,17 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 
 // ListCategories retrieves all categories with pagination
 func (h *CategoryHandler) ListCategories(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
-		return
-	}
-
-	offset, err := strconv.Atoi(offsetStr)
+	page, err := parsePageRequest(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset parameter"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	categories, err := h.categoryService.ListCategories(limit, offset)
+	result, err := h.categoryService.ListCategories(c.Request.Context(), page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"categories": categories})
+	writePage(c, result)
 }
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CollaboratorHandler struct {
+	collaboratorService service.CollaboratorService
+}
+
+func NewCollaboratorHandler(collaboratorService service.CollaboratorService) *CollaboratorHandler {
+	return &CollaboratorHandler{
+		collaboratorService: collaboratorService,
+	}
+}
+
+// collaborators returns the service limited to the request's workspace
+func (h *CollaboratorHandler) collaborators(c *gin.Context, userID uuid.UUID) service.CollaboratorService {
+	return h.collaboratorService.InWorkspace(getMembership(c, userID))
+}
+
+type ShareTaskRequest struct {
+	Permission model.TaskPermission `json:"permission" binding:"required"`
+}
+
+type AssignTaskRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// GetShares lists the users a task is shared with
+func (h *CollaboratorHandler) GetShares(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	shares, err := h.collaborators(c, userID).GetShares(c.Request.Context(), userID, taskID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+// ShareTask shares a task with a user or changes their permission
+func (h *CollaboratorHandler) ShareTask(c *gin.Context) {
+	userID, taskID, otherID, ok := collaboratorParams(c)
+	if !ok {
+		return
+	}
+
+	var req ShareTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	share, err := h.collaborators(c, userID).ShareTask(c.Request.Context(), userID, taskID, otherID, req.Permission)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"share": share})
+}
+
+// UnshareTask revokes a user's access to a task
+func (h *CollaboratorHandler) UnshareTask(c *gin.Context) {
+	userID, taskID, otherID, ok := collaboratorParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.collaborators(c, userID).UnshareTask(c.Request.Context(), userID, taskID, otherID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "share removed successfully"})
+}
+
+// GetAssignees lists the users assigned to a task
+func (h *CollaboratorHandler) GetAssignees(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	assignees, err := h.collaborators(c, userID).GetAssignees(c.Request.Context(), userID, taskID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assignees": assignees})
+}
+
+// AssignTask assigns a user to a task
+func (h *CollaboratorHandler) AssignTask(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var req AssignTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.collaborators(c, userID).AssignTask(c.Request.Context(), userID, taskID, req.UserID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user assigned successfully"})
+}
+
+// UnassignTask removes a user from a task's assignees
+func (h *CollaboratorHandler) UnassignTask(c *gin.Context) {
+	userID, taskID, otherID, ok := collaboratorParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.collaborators(c, userID).UnassignTask(c.Request.Context(), userID, taskID, otherID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unassigned successfully"})
+}
+
+// collaboratorParams reads the authenticated user and the task and user IDs
+// of a share or assignee route, writing the error response itself when one
+// is missing
+func collaboratorParams(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	otherID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, taskID, otherID, true
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CommentHandler struct {
+	commentService service.CommentService
+}
+
+func NewCommentHandler(commentService service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// comments returns the service limited to the request's workspace
+func (h *CommentHandler) comments(c *gin.Context, userID uuid.UUID) service.CommentService {
+	return h.commentService.InWorkspace(getMembership(c, userID))
+}
+
+type CommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CreateComment adds a comment to a task
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment := &model.Comment{
+		TaskID: taskID,
+		UserID: userID,
+		Body:   req.Body,
+	}
+
+	if err := h.comments(c, userID).CreateComment(c.Request.Context(), comment); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"comment": comment})
+}
+
+// GetComments lists the comments of a task, oldest first
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.comments(c, userID).GetComments(c.Request.Context(), userID, taskID, page)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePage(c, result)
+}
+
+// UpdateComment edits the body of a comment
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, taskID, commentID, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.comments(c, userID).UpdateComment(c.Request.Context(), userID, taskID, commentID, req.Body)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comment": comment})
+}
+
+// DeleteComment deletes a comment (soft delete)
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, taskID, commentID, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.comments(c, userID).DeleteComment(c.Request.Context(), userID, taskID, commentID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "comment deleted successfully"})
+}
+
+// GetCommentHistory lists the previous bodies of an edited comment
+func (h *CommentHandler) GetCommentHistory(c *gin.Context) {
+	userID, taskID, commentID, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.comments(c, userID).GetCommentHistory(c.Request.Context(), userID, taskID, commentID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": revisions})
+}
+
+// commentParams reads the authenticated user and the task and comment IDs of
+// a comment route, writing the error response itself when one is missing
+func commentParams(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	commentID, err := uuid.Parse(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, taskID, commentID, true
+}
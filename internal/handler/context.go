@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"Arise-test/internal/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// getUserID returns the authenticated user's ID set by the auth middleware.
+// It writes the error response itself and reports false when there is none.
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID format"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// getMembership returns the workspace membership set by the workspace
+// middleware. Requests that do not select a workspace act in the user's
+// personal workspace, which they own.
+func getMembership(c *gin.Context, userID uuid.UUID) model.Membership {
+	if value, exists := c.Get("membership"); exists {
+		if membership, ok := value.(model.Membership); ok {
+			return membership
+		}
+	}
+	return model.Membership{WorkspaceID: userID, UserID: userID, Role: model.WorkspaceRoleOwner}
+}
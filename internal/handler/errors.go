@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"Arise-test/internal/repository"
+	"Arise-test/internal/service"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// errorStatus maps errors returned by the services to an HTTP status code,
+// falling back to 500 for anything unexpected
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound),
+		errors.Is(err, service.ErrCategoryNotFound),
+		errors.Is(err, service.ErrProjectNotFound),
+		errors.Is(err, service.ErrMilestoneNotFound),
+		errors.Is(err, service.ErrBoardNotFound),
+		errors.Is(err, service.ErrColumnNotFound),
+		errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrRoleNotFound),
+		errors.Is(err, service.ErrDependencyNotFound),
+		errors.Is(err, service.ErrCommentNotFound),
+		errors.Is(err, service.ErrAttachmentNotFound),
+		errors.Is(err, service.ErrTagNotFound),
+		errors.Is(err, service.ErrShareNotFound),
+		errors.Is(err, service.ErrAssigneeNotFound),
+		errors.Is(err, service.ErrWorkspaceNotFound),
+		errors.Is(err, service.ErrMemberNotFound),
+		errors.Is(err, service.ErrInvitationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvitationExpired):
+		return http.StatusGone
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, repository.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrBulkAborted):
+		return http.StatusFailedDependency
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrAttachmentTypeNotAllowed):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, service.ErrNotCommentAuthor),
+		errors.Is(err, service.ErrTaskForbidden),
+		errors.Is(err, service.ErrWorkspaceForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrRoleInUse),
+		errors.Is(err, service.ErrLastAdmin),
+		errors.Is(err, service.ErrOpenSubtasks),
+		errors.Is(err, service.ErrTaskBlocked),
+		errors.Is(err, service.ErrDependencyExists),
+		errors.Is(err, service.ErrInvalidTransition),
+		errors.Is(err, service.ErrTagExists),
+		errors.Is(err, service.ErrLastOwner),
+		errors.Is(err, service.ErrPersonalWorkspace),
+		errors.Is(err, service.ErrUserHasContent),
+		errors.Is(err, service.ErrSoleWorkspaceOwner):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrUnknownPermission),
+		errors.Is(err, service.ErrParentTaskNotFound),
+		errors.Is(err, service.ErrTaskCycle),
+		errors.Is(err, service.ErrDependencyCycle),
+		errors.Is(err, service.ErrInvalidRecurrence),
+		errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrInvalidFilter),
+		errors.Is(err, repository.ErrInvalidCursor),
+		errors.Is(err, service.ErrInvalidComment),
+		errors.Is(err, service.ErrInvalidAttachment),
+		errors.Is(err, service.ErrInvalidTag),
+		errors.Is(err, service.ErrInvalidShare),
+		errors.Is(err, service.ErrInvalidWorkspace),
+		errors.Is(err, service.ErrInvalidInvitation),
+		errors.Is(err, service.ErrInvalidProject),
+		errors.Is(err, service.ErrInvalidBoard),
+		errors.Is(err, repository.ErrInvalidPosition),
+		errors.Is(err, service.ErrInvalidDeletePolicy),
+		errors.Is(err, service.ErrInvalidTask),
+		errors.Is(err, service.ErrInvalidCategory),
+		errors.Is(err, service.ErrInvalidBulk):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag is the entity tag of a resource at the given version
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// etagListed reports whether an If-Match or If-None-Match header value lists
+// the entity tag or is the "*" wildcard
+func etagListed(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch evaluates the If-Match precondition of a write against the
+// current version of the resource, writing 412 Precondition Failed when the
+// client's copy is stale. Requests without If-Match pass.
+func checkIfMatch(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || etagListed(header, etag(version)) {
+		return true
+	}
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "resource has been modified, fetch it again"})
+	return false
+}
+
+// ifMatchVersion returns the version named by the If-Match header of a write
+// that the service checks itself, or nil when the header is missing or "*".
+// Entity tags this API never issued cannot match and are answered with 412
+// Precondition Failed; a list of several tags is rejected with 400.
+func ifMatchVersion(c *gin.Context) (*int, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" || strings.TrimSpace(header) == "*" {
+		return nil, true
+	}
+
+	tags := strings.Split(header, ",")
+	if len(tags) > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match must name a single entity tag"})
+		return nil, false
+	}
+	tag := strings.TrimPrefix(strings.TrimSpace(tags[0]), "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || etag(version) != tag {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "resource has been modified, fetch it again"})
+		return nil, false
+	}
+	return &version, true
+}
+
+// writeVersioned responds to a read with the resource and its ETag, or with
+// 304 Not Modified when If-None-Match shows the client's copy is current
+func writeVersioned(c *gin.Context, version int, body gin.H) {
+	tag := etag(version)
+	c.Header("ETag", tag)
+	if header := c.GetHeader("If-None-Match"); header != "" && etagListed(header, tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, body)
+}
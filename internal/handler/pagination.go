@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"Arise-test/internal/model"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePageRequest reads the cursor, limit and include_total query
+// parameters of a list endpoint. Limits above model.MaxPageSize are clamped.
+func parsePageRequest(c *gin.Context) (model.PageRequest, error) {
+	page := model.PageRequest{Cursor: c.Query("cursor"), Limit: model.DefaultPageSize}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return page, errors.New("invalid limit parameter")
+		}
+		page.Limit = min(limit, model.MaxPageSize)
+	}
+
+	if value := c.Query("include_total"); value != "" {
+		withTotal, err := strconv.ParseBool(value)
+		if err != nil {
+			return page, errors.New("invalid include_total parameter")
+		}
+		page.WithTotal = withTotal
+	}
+
+	return page, nil
+}
+
+// writePage responds with a page in the list envelope and, when another page
+// follows, a Link header pointing at it
+func writePage[T any](c *gin.Context, page model.Page[T]) {
+	if page.HasMore {
+		next := *c.Request.URL
+		query := next.Query()
+		query.Set("cursor", page.NextCursor)
+		next.RawQuery = query.Encode()
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,23 @@
+package handler
+
+import "encoding/json"
+
+// Optional is a member of a JSON Merge Patch (RFC 7396) document. Set reports
+// whether the member was present; an explicit null leaves Value at its zero
+// value, which clears the field it is applied to.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// apply writes the member to dst when it was present in the patch
+func (o Optional[T]) apply(dst *T) {
+	if o.Set {
+		*dst = o.Value
+	}
+}
@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ProjectHandler struct {
+	projectService service.ProjectService
+}
+
+func NewProjectHandler(projectService service.ProjectService) *ProjectHandler {
+	return &ProjectHandler{
+		projectService: projectService,
+	}
+}
+
+// projects returns the service limited to the request's workspace
+func (h *ProjectHandler) projects(c *gin.Context, userID uuid.UUID) service.ProjectService {
+	return h.projectService.InWorkspace(getMembership(c, userID))
+}
+
+type CreateProjectRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Status      model.ProjectStatus `json:"status"`
+	StartDate   *time.Time          `json:"start_date"`
+	TargetDate  *time.Time          `json:"target_date"`
+}
+
+type UpdateProjectRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Status      model.ProjectStatus `json:"status"`
+	StartDate   *time.Time          `json:"start_date"`
+	TargetDate  *time.Time          `json:"target_date"`
+}
+
+type CreateMilestoneRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date"`
+}
+
+type UpdateMilestoneRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date"`
+}
+
+// CreateProject creates a new project
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var req CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	project := &model.Project{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+		StartDate:   req.StartDate,
+		TargetDate:  req.TargetDate,
+		UserID:      userID,
+	}
+
+	if err := h.projects(c, userID).CreateProject(c.Request.Context(), project); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"project": project})
+}
+
+// GetProject retrieves a project with its milestones
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	userID, id, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.projects(c, userID).GetProjectByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"project": project})
+}
+
+// GetUserProjects retrieves all projects for the authenticated user
+func (h *ProjectHandler) GetUserProjects(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	projects, err := h.projects(c, userID).GetProjectsByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"projects": projects})
+}
+
+// UpdateProject updates a project
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	userID, id, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.projects(c, userID).GetProjectByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update fields if provided
+	if req.Name != "" {
+		project.Name = req.Name
+	}
+	if req.Description != "" {
+		project.Description = req.Description
+	}
+	if req.Status != "" {
+		project.Status = req.Status
+	}
+	if req.StartDate != nil {
+		project.StartDate = req.StartDate
+	}
+	if req.TargetDate != nil {
+		project.TargetDate = req.TargetDate
+	}
+
+	if err := h.projects(c, userID).UpdateProject(c.Request.Context(), userID, project); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"project": project})
+}
+
+// DeleteProject deletes a project and its milestones
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	userID, id, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.projects(c, userID).DeleteProject(c.Request.Context(), userID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "project deleted successfully"})
+}
+
+// GetProjectProgress reports the open and completed tasks of a project and
+// of each of its milestones
+func (h *ProjectHandler) GetProjectProgress(c *gin.Context) {
+	userID, id, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.projects(c, userID).GetProjectProgress(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"progress": progress})
+}
+
+// CreateMilestone adds a milestone to a project
+func (h *ProjectHandler) CreateMilestone(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req CreateMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	milestone := &model.Milestone{
+		ProjectID:   projectID,
+		Name:        req.Name,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+	}
+
+	if err := h.projects(c, userID).CreateMilestone(c.Request.Context(), userID, milestone); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"milestone": milestone})
+}
+
+// UpdateMilestone updates a milestone of a project
+func (h *ProjectHandler) UpdateMilestone(c *gin.Context) {
+	userID, projectID, milestoneID, ok := milestoneParams(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	milestone, err := h.projects(c, userID).GetMilestone(c.Request.Context(), userID, projectID, milestoneID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update fields if provided
+	if req.Name != "" {
+		milestone.Name = req.Name
+	}
+	if req.Description != "" {
+		milestone.Description = req.Description
+	}
+	if req.DueDate != nil {
+		milestone.DueDate = req.DueDate
+	}
+
+	if err := h.projects(c, userID).UpdateMilestone(c.Request.Context(), userID, milestone); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"milestone": milestone})
+}
+
+// DeleteMilestone deletes a milestone, keeping its tasks in the project
+func (h *ProjectHandler) DeleteMilestone(c *gin.Context) {
+	userID, projectID, milestoneID, ok := milestoneParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.projects(c, userID).DeleteMilestone(c.Request.Context(), userID, projectID, milestoneID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "milestone deleted successfully"})
+}
+
+// GetMilestoneProgress reports the open and completed tasks of a milestone
+func (h *ProjectHandler) GetMilestoneProgress(c *gin.Context) {
+	userID, projectID, milestoneID, ok := milestoneParams(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.projects(c, userID).GetMilestoneProgress(c.Request.Context(), userID, projectID, milestoneID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"progress": progress})
+}
+
+// projectParams reads the authenticated user and the project ID of a project
+// route, writing the error response itself when one is missing
+func projectParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, id, true
+}
+
+// milestoneParams reads the authenticated user and the project and milestone
+// IDs of a milestone route, writing the error response itself when one is
+// missing
+func milestoneParams(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	milestoneID, err := uuid.Parse(c.Param("milestoneId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid milestone ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, projectID, milestoneID, true
+}
@@ -3,8 +3,12 @@ package handler
 import (
 	"Arise-test/internal/model"
 	"Arise-test/internal/service"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -21,21 +25,86 @@ func NewTaskHandler(taskService service.TaskService) *TaskHandler {
 	}
 }
 
+// tasks returns the service limited to the request's workspace
+func (h *TaskHandler) tasks(c *gin.Context, userID uuid.UUID) service.TaskService {
+	return h.taskService.InWorkspace(getMembership(c, userID))
+}
+
 type CreateTaskRequest struct {
 	Title       string             `json:"title" binding:"required"`
 	Description string             `json:"description"`
 	Priority    model.TaskPriority `json:"priority"`
 	DueDate     *time.Time         `json:"due_date"`
 	CategoryID  *uuid.UUID         `json:"category_id"`
+	ProjectID   *uuid.UUID         `json:"project_id"`
+	MilestoneID *uuid.UUID         `json:"milestone_id"`
+	ParentID    *uuid.UUID         `json:"parent_id"`
+	Recurrence  string             `json:"recurrence"`
 }
 
+// UpdateTaskRequest replaces every writable field of a task; omitted fields
+// are cleared
 type UpdateTaskRequest struct {
-	Title       string             `json:"title"`
+	Title       string             `json:"title" binding:"required"`
 	Description string             `json:"description"`
-	Status      model.TaskStatus   `json:"status"`
+	Status      model.TaskStatus   `json:"status" binding:"required"`
 	Priority    model.TaskPriority `json:"priority"`
 	DueDate     *time.Time         `json:"due_date"`
 	CategoryID  *uuid.UUID         `json:"category_id"`
+	ProjectID   *uuid.UUID         `json:"project_id"`
+	MilestoneID *uuid.UUID         `json:"milestone_id"`
+	ParentID    *uuid.UUID         `json:"parent_id"`
+	Recurrence  string             `json:"recurrence"`
+}
+
+// PatchTaskRequest is a JSON Merge Patch of a task: absent members are left
+// untouched and null clears a field
+type PatchTaskRequest struct {
+	Title       Optional[string]             `json:"title"`
+	Description Optional[string]             `json:"description"`
+	Status      Optional[model.TaskStatus]   `json:"status"`
+	Priority    Optional[model.TaskPriority] `json:"priority"`
+	DueDate     Optional[*time.Time]         `json:"due_date"`
+	CategoryID  Optional[*uuid.UUID]         `json:"category_id"`
+	ProjectID   Optional[*uuid.UUID]         `json:"project_id"`
+	MilestoneID Optional[*uuid.UUID]         `json:"milestone_id"`
+	ParentID    Optional[*uuid.UUID]         `json:"parent_id"`
+	Recurrence  Optional[string]             `json:"recurrence"`
+}
+
+// BulkTaskRequest applies one action to many tasks. Create takes the new
+// tasks; update and delete select tasks by ids or, without ids, by a filter
+// written like the query string of GET /tasks (e.g. "status=pending&overdue=true").
+type BulkTaskRequest struct {
+	Action  string              `json:"action" binding:"required,oneof=create update delete"`
+	Mode    string              `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Tasks   []CreateTaskRequest `json:"tasks" binding:"dive"`
+	IDs     []uuid.UUID         `json:"ids"`
+	Filter  string              `json:"filter"`
+	Changes BulkTaskChanges     `json:"changes"`
+}
+
+// BulkTaskChanges are the fields a bulk update can set, as a merge patch
+type BulkTaskChanges struct {
+	Status     Optional[model.TaskStatus]   `json:"status"`
+	Priority   Optional[model.TaskPriority] `json:"priority"`
+	CategoryID Optional[*uuid.UUID]         `json:"category_id"`
+	DueDate    Optional[*time.Time]         `json:"due_date"`
+}
+
+type MoveTaskRequest struct {
+	ColumnID uuid.UUID  `json:"column_id" binding:"required"`
+	AfterID  *uuid.UUID `json:"after_id"`
+}
+
+type AddDependencyRequest struct {
+	DependsOnID uuid.UUID `json:"depends_on_id" binding:"required"`
+}
+
+type UpdateTaskStatusRequest struct {
+	Status model.TaskStatus `json:"status" binding:"required"`
+	Reason string           `json:"reason"`
+	Force  bool             `json:"force"`
 }
 
 // CreateTask creates a new task
@@ -46,16 +115,9 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from context (should be set by auth middleware)
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
-		return
-	}
-
-	userID, ok := userIDInterface.(uuid.UUID)
+	// Get user ID from context (set by auth middleware)
+	userID, ok := getUserID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID format"})
 		return
 	}
 
@@ -65,12 +127,16 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		Priority:    req.Priority,
 		DueDate:     req.DueDate,
 		CategoryID:  req.CategoryID,
+		ProjectID:   req.ProjectID,
+		MilestoneID: req.MilestoneID,
+		ParentID:    req.ParentID,
+		Recurrence:  req.Recurrence,
 		UserID:      userID,
 		Status:      model.TaskStatusPending,
 	}
 
-	if err := h.taskService.CreateTask(task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.tasks(c, userID).CreateTask(c.Request.Context(), task); err != nil {
+		c.JSON(taskWriteStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -79,6 +145,11 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 // GetTask retrieves a task by ID
 func (h *TaskHandler) GetTask(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -86,64 +157,213 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.taskService.GetTaskByID(id)
+	task, err := h.tasks(c, userID).GetTaskByID(c.Request.Context(), userID, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"task": task})
+	writeVersioned(c, task.Version, gin.H{"task": task})
 }
 
 // GetUserTasks retrieves all tasks for the authenticated user
 func (h *TaskHandler) GetUserTasks(c *gin.Context) {
 	// Get user ID from context
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+	userID, ok := getUserID(c)
+	if !ok {
 		return
 	}
 
-	userID, ok := userIDInterface.(uuid.UUID)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID format"})
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-	status := c.Query("status")
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	limit, err := strconv.Atoi(limitStr)
+	result, err := h.tasks(c, userID).FindTasks(c.Request.Context(), userID, filter, page)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePage(c, result)
+}
+
+// GetAssignedTasks lists the tasks the authenticated user is assigned to,
+// accepting the same parameters as GetUserTasks
+func (h *TaskHandler) GetAssignedTasks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	filter, err := parseTaskFilter(c.Request.URL.Query())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset parameter"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var tasks []model.Task
-	if status != "" {
-		taskStatus := model.TaskStatus(status)
-		tasks, err = h.taskService.GetTasksByStatus(userID, taskStatus, limit, offset)
-	} else {
-		tasks, err = h.taskService.GetTasksByUserID(userID, limit, offset)
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
+	result, err := h.tasks(c, userID).FindAssignedTasks(c.Request.Context(), userID, filter, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+	writePage(c, result)
+}
+
+// parseTaskFilter reads the filter and sort query parameters of the task
+// list. List parameters accept comma-separated or repeated values.
+func parseTaskFilter(query url.Values) (model.TaskFilter, error) {
+	var filter model.TaskFilter
+	var err error
+
+	for _, status := range queryList(query, "status") {
+		filter.Statuses = append(filter.Statuses, model.TaskStatus(status))
+	}
+	for _, priority := range queryList(query, "priority") {
+		filter.Priorities = append(filter.Priorities, model.TaskPriority(priority))
+	}
+
+	if categoryID := query.Get("category_id"); categoryID != "" {
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			return filter, errors.New("invalid category_id parameter")
+		}
+		filter.CategoryID = &id
+	}
+
+	if projectID := query.Get("project_id"); projectID != "" {
+		id, err := uuid.Parse(projectID)
+		if err != nil {
+			return filter, errors.New("invalid project_id parameter")
+		}
+		filter.ProjectID = &id
+	}
+
+	if milestoneID := query.Get("milestone_id"); milestoneID != "" {
+		id, err := uuid.Parse(milestoneID)
+		if err != nil {
+			return filter, errors.New("invalid milestone_id parameter")
+		}
+		filter.MilestoneID = &id
+	}
+
+	for _, tagID := range queryList(query, "tag_id") {
+		id, err := uuid.Parse(tagID)
+		if err != nil {
+			return filter, errors.New("invalid tag_id parameter")
+		}
+		filter.TagIDs = append(filter.TagIDs, id)
+	}
+	switch defaultQuery(query, "tag_match", "any") {
+	case "any":
+	case "all":
+		filter.AllTags = true
+	default:
+		return filter, errors.New("invalid tag_match parameter, use any or all")
+	}
+
+	times := []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"due_before", &filter.DueBefore},
+		{"due_after", &filter.DueAfter},
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+		{"updated_after", &filter.UpdatedAfter},
+		{"updated_before", &filter.UpdatedBefore},
+	}
+	for _, t := range times {
+		if *t.dest, err = parseTimeQuery(query, t.param); err != nil {
+			return filter, err
+		}
+	}
+
+	if value := query.Get("has_due_date"); value != "" {
+		hasDueDate, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.New("invalid has_due_date parameter")
+		}
+		filter.HasDueDate = &hasDueDate
+	}
+	if value := query.Get("overdue"); value != "" {
+		if filter.Overdue, err = strconv.ParseBool(value); err != nil {
+			return filter, errors.New("invalid overdue parameter")
+		}
+	}
+
+	filter.Query = strings.TrimSpace(query.Get("q"))
+	filter.SortBy = model.TaskSortField(query.Get("sort"))
+
+	switch defaultQuery(query, "order", "asc") {
+	case "asc":
+	case "desc":
+		filter.SortDesc = true
+	default:
+		return filter, errors.New("invalid order parameter, use asc or desc")
+	}
+
+	return filter, nil
+}
+
+// queryList collects a query parameter given as repeated and/or
+// comma-separated values
+func queryList(query url.Values, key string) []string {
+	var values []string
+	for _, value := range query[key] {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
 }
 
-// UpdateTask updates a task
+// defaultQuery returns a query parameter, or fallback when it is empty
+func defaultQuery(query url.Values, key, fallback string) string {
+	if value := query.Get(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// parseTimeQuery reads an RFC 3339 timestamp or a YYYY-MM-DD date
+func parseTimeQuery(query url.Values, key string) (*time.Time, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid %s parameter, use RFC 3339 or YYYY-MM-DD", key)
+}
+
+// UpdateTask replaces a task with the request body
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -157,42 +377,441 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.taskService.GetTaskByID(id)
+	task, err := h.tasks(c, userID).GetTaskByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkIfMatch(c, task.Version) {
+		return
+	}
+
+	task.Title = req.Title
+	task.Description = req.Description
+	task.Status = req.Status
+	task.Priority = req.Priority
+	task.DueDate = req.DueDate
+	task.CategoryID = req.CategoryID
+	task.ProjectID = req.ProjectID
+	task.MilestoneID = req.MilestoneID
+	task.ParentID = req.ParentID
+	task.Recurrence = req.Recurrence
+
+	h.saveTask(c, userID, task)
+}
+
+// PatchTask applies a JSON Merge Patch to a task
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var req PatchTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := h.tasks(c, userID).GetTaskByID(c.Request.Context(), userID, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkIfMatch(c, task.Version) {
+		return
+	}
+
+	req.Title.apply(&task.Title)
+	req.Description.apply(&task.Description)
+	req.Status.apply(&task.Status)
+	req.Priority.apply(&task.Priority)
+	req.DueDate.apply(&task.DueDate)
+	req.CategoryID.apply(&task.CategoryID)
+	req.ProjectID.apply(&task.ProjectID)
+	req.MilestoneID.apply(&task.MilestoneID)
+	req.ParentID.apply(&task.ParentID)
+	req.Recurrence.apply(&task.Recurrence)
+
+	h.saveTask(c, userID, task)
+}
+
+// saveTask stores an updated task and responds with it
+func (h *TaskHandler) saveTask(c *gin.Context, userID uuid.UUID, task *model.Task) {
+	if err := h.tasks(c, userID).UpdateTask(c.Request.Context(), userID, task); err != nil {
+		c.JSON(taskWriteStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", etag(task.Version))
+	c.JSON(http.StatusOK, gin.H{"task": task})
+}
+
+// taskWriteStatus maps an error from creating or updating a task. References
+// to categories, projects or milestones that do not exist are the client's
+// mistake rather than a missing task.
+func taskWriteStatus(err error) int {
+	if errors.Is(err, service.ErrCategoryNotFound) || errors.Is(err, service.ErrProjectNotFound) || errors.Is(err, service.ErrMilestoneNotFound) {
+		return http.StatusBadRequest
+	}
+	return errorStatus(err)
+}
+
+// BulkTasks creates, updates or deletes many tasks in one transaction. In
+// the default atomic mode a failing item leaves every task unchanged; in
+// best_effort mode the other items are still applied.
+func (h *TaskHandler) BulkTasks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req BulkTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	atomic := req.Mode != "best_effort"
+
+	target := service.BulkTarget{IDs: req.IDs}
+	if req.Action != "create" && len(req.IDs) == 0 && req.Filter != "" {
+		query, err := url.ParseQuery(req.Filter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid filter"})
+			return
+		}
+		filter, err := parseTaskFilter(query)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		target.Filter = &filter
+	}
+
+	tasks := h.tasks(c, userID)
+	var results []service.BulkResult
+	var err error
+	switch req.Action {
+	case "create":
+		newTasks := make([]*model.Task, len(req.Tasks))
+		for i, t := range req.Tasks {
+			newTasks[i] = &model.Task{
+				Title:       t.Title,
+				Description: t.Description,
+				Priority:    t.Priority,
+				DueDate:     t.DueDate,
+				CategoryID:  t.CategoryID,
+				ProjectID:   t.ProjectID,
+				MilestoneID: t.MilestoneID,
+				ParentID:    t.ParentID,
+				Recurrence:  t.Recurrence,
+				Status:      model.TaskStatusPending,
+			}
+		}
+		results, err = tasks.BulkCreateTasks(c.Request.Context(), userID, newTasks, atomic)
+	case "update":
+		changes := req.Changes
+		if !changes.Status.Set && !changes.Priority.Set && !changes.CategoryID.Set && !changes.DueDate.Set {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "changes must set status, priority, category_id or due_date"})
+			return
+		}
+		results, err = tasks.BulkUpdateTasks(c.Request.Context(), userID, target, func(task *model.Task) {
+			changes.Status.apply(&task.Status)
+			changes.Priority.apply(&task.Priority)
+			changes.CategoryID.apply(&task.CategoryID)
+			changes.DueDate.apply(&task.DueDate)
+		}, atomic)
+	case "delete":
+		results, err = tasks.BulkDeleteTasks(c.Request.Context(), userID, target, atomic)
+	}
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writeBulkResults(c, req.Action, results)
+}
+
+// writeBulkResults responds with the outcome of every item: 200 (201 for
+// creates) when all items were applied and 207 when only some were. When none
+// were, it takes the status of the first failure that was not an abort.
+func writeBulkResults(c *gin.Context, action string, results []service.BulkResult) {
+	success := http.StatusOK
+	if action == "create" {
+		success = http.StatusCreated
+	}
+
+	items := make([]gin.H, len(results))
+	applied, failure := 0, 0
+	for i, result := range results {
+		item := gin.H{"index": i, "id": result.ID, "status": success}
+		if result.Err != nil {
+			item["status"] = errorStatus(result.Err)
+			item["error"] = result.Err.Error()
+			if failure == 0 && !errors.Is(result.Err, service.ErrBulkAborted) {
+				failure = item["status"].(int)
+			}
+		} else {
+			applied++
+			if result.Task != nil {
+				item["task"] = result.Task
+			}
+		}
+		items[i] = item
+	}
+
+	status := success
+	switch {
+	case applied == len(results):
+	case applied > 0:
+		status = http.StatusMultiStatus
+	case failure != 0:
+		status = failure
+	default:
+		status = http.StatusFailedDependency
+	}
+
+	c.JSON(status, gin.H{"results": items, "applied": applied, "failed": len(results) - applied})
+}
+
+// UpdateTaskStatus changes the status of a task
+func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var req UpdateTaskStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	// Update fields if provided
-	if req.Title != "" {
-		task.Title = req.Title
+	if err := h.tasks(c, userID).UpdateTaskStatus(c.Request.Context(), userID, id, version, req.Status, req.Reason, req.Force); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
-	if req.Description != "" {
-		task.Description = req.Description
+
+	task, err := h.tasks(c, userID).GetTaskByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
-	if req.Status != "" {
-		task.Status = req.Status
+
+	c.Header("ETag", etag(task.Version))
+	c.JSON(http.StatusOK, gin.H{"task": task})
+}
+
+// MoveTask places a task in a board column after another task, or at the top
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
 	}
-	if req.Priority != "" {
-		task.Priority = req.Priority
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
 	}
-	if req.DueDate != nil {
-		task.DueDate = req.DueDate
+
+	var req MoveTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	if req.CategoryID != nil {
-		task.CategoryID = req.CategoryID
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
 	}
 
-	if err := h.taskService.UpdateTask(task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	task, err := h.tasks(c, userID).MoveTask(c.Request.Context(), userID, id, version, req.ColumnID, req.AfterID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", etag(task.Version))
 	c.JSON(http.StatusOK, gin.H{"task": task})
 }
 
+// GetStatusHistory retrieves the status transitions of a task
+func (h *TaskHandler) GetStatusHistory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	history, err := h.tasks(c, userID).GetStatusHistory(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// GetSubtasks retrieves the direct subtasks of a task
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	tasks, err := h.tasks(c, userID).GetSubtasks(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// GetTaskTree retrieves a task with all nested subtasks and their progress
+func (h *TaskHandler) GetTaskTree(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	tree, err := h.tasks(c, userID).GetTaskTree(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task": tree})
+}
+
+// GetDependencies lists the tasks a task is blocked by
+func (h *TaskHandler) GetDependencies(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	tasks, err := h.tasks(c, userID).GetDependencies(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// AddDependency marks a task as blocked by another task
+func (h *TaskHandler) AddDependency(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var req AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tasks(c, userID).AddDependency(c.Request.Context(), userID, id, req.DependsOnID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "dependency added successfully"})
+}
+
+// RemoveDependency removes a blocked-by relation between two tasks
+func (h *TaskHandler) RemoveDependency(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	dependsOnID, err := uuid.Parse(c.Param("dependsOnId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dependency task ID"})
+		return
+	}
+
+	if err := h.tasks(c, userID).RemoveDependency(c.Request.Context(), userID, id, dependsOnID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "dependency removed successfully"})
+}
+
 // DeleteTask deletes a task
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -200,10 +819,32 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := h.taskService.DeleteTask(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tasks(c, userID).DeleteTask(c.Request.Context(), userID, id, version); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "task deleted successfully"})
 }
+
+// ListTasks retrieves all tasks with pagination
+func (h *TaskHandler) ListTasks(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.taskService.ListTasks(c.Request.Context(), page)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePage(c, result)
+}
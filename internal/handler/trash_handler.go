@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"Arise-test/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TrashHandler struct {
+	trashService service.TrashService
+}
+
+func NewTrashHandler(trashService service.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// trash returns the service limited to the request's workspace
+func (h *TrashHandler) trash(c *gin.Context, userID uuid.UUID) service.TrashService {
+	return h.trashService.InWorkspace(getMembership(c, userID))
+}
+
+// GetTrash lists the authenticated user's deleted tasks and categories
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	trash, err := h.trash(c, userID).GetTrash(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trash": trash})
+}
+
+// EmptyTrash permanently deletes everything in the user's trash
+func (h *TrashHandler) EmptyTrash(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.trash(c, userID).EmptyTrash(c.Request.Context(), userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "trash emptied successfully"})
+}
+
+// RestoreTask brings back a deleted task
+func (h *TrashHandler) RestoreTask(c *gin.Context) {
+	userID, id, ok := trashParams(c, "invalid task ID")
+	if !ok {
+		return
+	}
+
+	if err := h.trash(c, userID).RestoreTask(c.Request.Context(), userID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "task restored successfully"})
+}
+
+// RestoreCategory brings back a deleted category
+func (h *TrashHandler) RestoreCategory(c *gin.Context) {
+	userID, id, ok := trashParams(c, "invalid category ID")
+	if !ok {
+		return
+	}
+
+	if err := h.trash(c, userID).RestoreCategory(c.Request.Context(), userID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category restored successfully"})
+}
+
+// PurgeTask permanently deletes a deleted task
+func (h *TrashHandler) PurgeTask(c *gin.Context) {
+	userID, id, ok := trashParams(c, "invalid task ID")
+	if !ok {
+		return
+	}
+
+	if err := h.trash(c, userID).PurgeTask(c.Request.Context(), userID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "task purged successfully"})
+}
+
+// PurgeCategory permanently deletes a deleted category
+func (h *TrashHandler) PurgeCategory(c *gin.Context) {
+	userID, id, ok := trashParams(c, "invalid category ID")
+	if !ok {
+		return
+	}
+
+	if err := h.trash(c, userID).PurgeCategory(c.Request.Context(), userID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category purged successfully"})
+}
+
+// trashParams reads the authenticated user and the ID of a trashed item,
+// writing the error response itself when one is missing
+func trashParams(c *gin.Context, invalidID string) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidID})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, id, true
+}
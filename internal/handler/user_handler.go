@@ -4,7 +4,6 @@ import (
 	"Arise-test/internal/model"
 	"Arise-test/internal/service"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -28,11 +27,20 @@ type CreateUserRequest struct {
 	LastName  string `json:"last_name"`
 }
 
+// UpdateUserRequest replaces the profile of a user; omitted names are
+// cleared
 type UpdateUserRequest struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 }
 
+// PatchUserRequest is a JSON Merge Patch of a user profile: absent members
+// are left untouched and null clears a name
+type PatchUserRequest struct {
+	FirstName Optional[string] `json:"first_name"`
+	LastName  Optional[string] `json:"last_name"`
+}
+
 // CreateUser creates a new user
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req CreateUserRequest
@@ -49,7 +57,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		LastName:  req.LastName,
 	}
 
-	if err := h.userService.CreateUser(user); err != nil {
+	if err := h.userService.CreateUser(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -68,7 +76,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(id)
+	user, err := h.userService.GetUserByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
@@ -76,11 +84,16 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 	// Remove password from response
 	user.Password = ""
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	writeVersioned(c, user.Version, gin.H{"user": user})
 }
 
 // UpdateUser updates user information
 func (h *UserHandler) UpdateUser(c *gin.Context) {
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -94,7 +107,12 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
@@ -103,18 +121,24 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	user.FirstName = req.FirstName
 	user.LastName = req.LastName
 
-	if err := h.userService.UpdateUser(user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.userService.UpdateUser(c.Request.Context(), actorID, user, version); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	// Remove password from response
 	user.Password = ""
+	c.Header("ETag", etag(user.Version))
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
 
-// DeleteUser deletes a user
-func (h *UserHandler) DeleteUser(c *gin.Context) {
+// PatchUser applies a JSON Merge Patch to a user profile
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -122,41 +146,83 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.DeleteUser(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var req PatchUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	req.FirstName.apply(&user.FirstName)
+	req.LastName.apply(&user.LastName)
+
+	if err := h.userService.UpdateUser(c.Request.Context(), actorID, user, version); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Remove password from response
+	user.Password = ""
+	c.Header("ETag", etag(user.Version))
+	c.JSON(http.StatusOK, gin.H{"user": user})
 }
 
-// ListUsers retrieves all users with pagination
-func (h *UserHandler) ListUsers(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
+// DeleteUser deletes a user, refusing while they still own tasks or
+// categories unless ?policy=cascade deletes those too
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
 
-	limit, err := strconv.Atoi(limitStr)
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(c.Request.Context(), actorID, id, version, model.UserDeletePolicy(c.Query("policy"))); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
+}
+
+// ListUsers retrieves all users with pagination
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	page, err := parsePageRequest(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset parameter"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	users, err := h.userService.ListUsers(limit, offset)
+	result, err := h.userService.ListUsers(c.Request.Context(), page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	// Remove passwords from response
-	for i := range users {
-		users[i].Password = ""
+	for i := range result.Items {
+		result.Items[i].Password = ""
 	}
 
-	c.JSON(http.StatusOK, gin.H{"users": users})
+	writePage(c, result)
 }
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WorkspaceHandler struct {
+	workspaceService service.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+	}
+}
+
+type WorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type MemberRoleRequest struct {
+	Role model.WorkspaceRole `json:"role" binding:"required"`
+}
+
+type InvitationRequest struct {
+	Email string              `json:"email" binding:"required,email"`
+	Role  model.WorkspaceRole `json:"role" binding:"required"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type RegisterWithInvitationRequest struct {
+	Token     string `json:"token" binding:"required"`
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password" binding:"required,min=6"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// CreateWorkspace creates a team workspace owned by the user
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace := &model.Workspace{Name: req.Name}
+	if err := h.workspaceService.CreateWorkspace(c.Request.Context(), userID, workspace); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"workspace": workspace})
+}
+
+// GetUserWorkspaces lists the workspaces the user is a member of
+func (h *WorkspaceHandler) GetUserWorkspaces(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	workspaces, err := h.workspaceService.GetWorkspaces(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspaces": workspaces})
+}
+
+// GetWorkspace retrieves a workspace by ID
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	userID, id, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspace": workspace})
+}
+
+// UpdateWorkspace renames a workspace
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	userID, id, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace := &model.Workspace{ID: id, Name: req.Name}
+	if err := h.workspaceService.UpdateWorkspace(c.Request.Context(), userID, workspace); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspace": workspace})
+}
+
+// DeleteWorkspace deletes a team workspace with its tasks and categories
+func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+	userID, id, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.DeleteWorkspace(c.Request.Context(), userID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "workspace deleted successfully"})
+}
+
+// GetMembers lists the members of a workspace with their roles
+func (h *WorkspaceHandler) GetMembers(c *gin.Context) {
+	userID, id, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.workspaceService.GetMembers(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// UpdateMemberRole changes the role of a member
+func (h *WorkspaceHandler) UpdateMemberRole(c *gin.Context) {
+	userID, id, memberID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	var req MemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.workspaceService.UpdateMemberRole(c.Request.Context(), userID, id, memberID, req.Role)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"member": member})
+}
+
+// RemoveMember removes a member from a workspace, or lets the user leave it
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID, id, memberID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(c.Request.Context(), userID, id, memberID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed successfully"})
+}
+
+// CreateInvitation invites an email address to a workspace. The token is
+// only returned here.
+func (h *WorkspaceHandler) CreateInvitation(c *gin.Context) {
+	userID, id, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req InvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation := &model.Invitation{WorkspaceID: id, Email: req.Email, Role: req.Role}
+	token, err := h.workspaceService.CreateInvitation(c.Request.Context(), userID, invitation)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invitation": invitation, "token": token})
+}
+
+// GetInvitations lists the pending invitations of a workspace
+func (h *WorkspaceHandler) GetInvitations(c *gin.Context) {
+	userID, id, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.workspaceService.GetInvitations(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// RevokeInvitation deletes a pending invitation
+func (h *WorkspaceHandler) RevokeInvitation(c *gin.Context) {
+	userID, id, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	invitationID, err := uuid.Parse(c.Param("invitationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation ID"})
+		return
+	}
+
+	if err := h.workspaceService.RevokeInvitation(c.Request.Context(), userID, id, invitationID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked successfully"})
+}
+
+// AcceptInvitation adds the authenticated user to the workspace they were
+// invited to
+func (h *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	membership, err := h.workspaceService.AcceptInvitation(c.Request.Context(), userID, req.Token)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"membership": membership})
+}
+
+// RegisterWithInvitation creates an account for the invited email address
+// and adds it to the workspace
+func (h *WorkspaceHandler) RegisterWithInvitation(c *gin.Context) {
+	var req RegisterWithInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := &model.User{
+		Username:  req.Username,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	}
+
+	membership, err := h.workspaceService.RegisterWithInvitation(c.Request.Context(), req.Token, user)
+	if err != nil {
+		status := errorStatus(err)
+		// Account validation errors are reported like in CreateUser
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Remove password from response
+	user.Password = ""
+	c.JSON(http.StatusCreated, gin.H{"user": user, "membership": membership})
+}
+
+// workspaceParams reads the authenticated user and the workspace ID of a
+// workspace route, writing the error response itself when one is missing
+func workspaceParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workspace ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, id, true
+}
+
+// memberParams is workspaceParams for routes that also name a member
+func memberParams(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, id, ok := workspaceParams(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, id, memberID, true
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"Arise-test/internal/service"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader makes a POST safe to retry: the first response sent
+// for a key is stored and replayed for later requests with the same key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response that was replayed
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// replayedHeaders are the response headers stored and replayed with the body
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Idempotency replays the stored response to requests that repeat an
+// Idempotency-Key of the same user, and rejects a key reused for a different
+// request with 409 Conflict. Keys of anonymous requests are scoped to the
+// client address instead. Requests without the header, and responses with a
+// server error, are not stored. It must run after AuthMiddleware on
+// authenticated routes.
+func Idempotency(idempotencyService service.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(c, body)
+		value, _ := c.Get("userID")
+		userID, ok := value.(uuid.UUID)
+		if !ok {
+			// Anonymous keys are scoped to the client, so a retry with a
+			// different body is still rejected while other clients can
+			// neither replay nor block the key
+			userID = uuid.NewSHA1(uuid.Nil, []byte(c.ClientIP()))
+		}
+
+		stored, err := idempotencyService.Begin(c.Request.Context(), userID, key, fingerprint)
+		if err != nil {
+			c.AbortWithStatusJSON(idempotencyErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		if stored != nil {
+			contentType := "application/json; charset=utf-8"
+			for name, value := range stored.Headers {
+				if name == "Content-Type" {
+					contentType = value
+					continue
+				}
+				c.Header(name, value)
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, contentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// The outcome is recorded even when the request timed out or the
+		// client went away
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// Free the key when the handler panics or fails, so the client can
+		// retry the request
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := idempotencyService.Release(ctx, userID, key); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+		}()
+
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		headers := map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := idempotencyService.Complete(ctx, userID, key, recorder.Status(), headers, recorder.body.Bytes()); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// requestFingerprint identifies a request by its method, path, workspace and
+// body, so that reusing a key for a different request can be detected
+func requestFingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{c.Request.Method, c.Request.URL.Path, c.GetHeader(WorkspaceHeader)} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func idempotencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidIdempotencyKey):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrIdempotencyKeyReused),
+		errors.Is(err, service.ErrRequestInProgress):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// responseRecorder keeps a copy of the response body while writing it
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
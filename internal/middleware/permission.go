@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequirePermission only lets the request through when the authenticated
+// user's role grants the permission. It must run after AuthMiddleware.
+func RequirePermission(roleService service.RoleService, permission model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("userID")
+		userID, ok := value.(uuid.UUID)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			return
+		}
+
+		allowed, err := roleService.HasPermission(c.Request.Context(), userID, permission)
+		if err != nil && !errors.Is(err, service.ErrUserNotFound) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireIfMatch rejects writes that do not send If-Match with 428
+// Precondition Required, so clients cannot overwrite changes they have not
+// seen. When required is false every request is let through and If-Match is
+// only checked when present.
+func RequireIfMatch(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if required && c.GetHeader("If-Match") == "" {
+			c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+			return
+		}
+
+		c.Next()
+	}
+}
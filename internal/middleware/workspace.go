@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"Arise-test/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WorkspaceHeader selects the workspace a request acts in. Without it,
+// requests act in the user's personal workspace.
+const WorkspaceHeader = "X-Workspace-ID"
+
+// WorkspaceMiddleware checks that the authenticated user is a member of the
+// workspace selected by WorkspaceHeader and stores the membership in the
+// context under "membership". It must run after AuthMiddleware.
+func WorkspaceMiddleware(workspaceService service.WorkspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(WorkspaceHeader)
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		workspaceID, err := uuid.Parse(header)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid workspace ID"})
+			return
+		}
+
+		value, _ := c.Get("userID")
+		userID, ok := value.(uuid.UUID)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			return
+		}
+
+		membership, err := workspaceService.GetMembership(c.Request.Context(), userID, workspaceID)
+		if err != nil {
+			if errors.Is(err, service.ErrWorkspaceNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set("membership", *membership)
+		c.Next()
+	}
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
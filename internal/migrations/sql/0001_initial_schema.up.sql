@@ -0,0 +1,72 @@
+-- Initial schema. Tables use IF NOT EXISTS so databases previously created
+-- by GORM AutoMigrate are adopted; columns they lack are added below.
+
+CREATE TABLE IF NOT EXISTS users (
+    id          uuid PRIMARY KEY,
+    username    text NOT NULL,
+    email       text NOT NULL,
+    password    text NOT NULL,
+    first_name  text,
+    last_name   text,
+    role        text NOT NULL DEFAULT 'user',
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id          uuid PRIMARY KEY,
+    name        text NOT NULL,
+    description text,
+    color       text,
+    user_id     uuid NOT NULL REFERENCES users (id),
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_categories_user_id ON categories (user_id);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id          uuid PRIMARY KEY,
+    title       text NOT NULL,
+    description text,
+    status      text DEFAULT 'pending',
+    priority    text DEFAULT 'medium',
+    due_date    timestamptz,
+    user_id     uuid NOT NULL REFERENCES users (id),
+    category_id uuid REFERENCES categories (id),
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id ON tasks (user_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_category_id ON tasks (category_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks (deleted_at);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id          uuid PRIMARY KEY,
+    user_id     uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id   uuid NOT NULL,
+    token_hash  text NOT NULL,
+    expires_at  timestamptz NOT NULL,
+    revoked_at  timestamptz,
+    created_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id          uuid PRIMARY KEY,
+    name        text NOT NULL,
+    description text,
+    permissions jsonb NOT NULL DEFAULT '[]',
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles (name);
@@ -0,0 +1,8 @@
+CREATE TABLE task_dependencies (
+    task_id       uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    depends_on_id uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    created_at    timestamptz,
+    PRIMARY KEY (task_id, depends_on_id),
+    CHECK (task_id <> depends_on_id)
+);
+CREATE INDEX idx_task_dependencies_depends_on_id ON task_dependencies (depends_on_id);
@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN recurrence text NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN series_id uuid REFERENCES tasks (id);
+ALTER TABLE tasks ADD COLUMN occurrence integer NOT NULL DEFAULT 1;
+CREATE INDEX idx_tasks_series_id ON tasks (series_id);
@@ -0,0 +1,10 @@
+CREATE TABLE task_status_changes (
+    id          uuid PRIMARY KEY,
+    task_id     uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id     uuid NOT NULL REFERENCES users (id),
+    from_status text NOT NULL,
+    to_status   text NOT NULL,
+    reason      text,
+    created_at  timestamptz
+);
+CREATE INDEX idx_task_status_changes_task_id ON task_status_changes (task_id);
@@ -0,0 +1,27 @@
+CREATE TABLE comments (
+    id         uuid PRIMARY KEY,
+    task_id    uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id    uuid NOT NULL REFERENCES users (id),
+    body       text NOT NULL,
+    edited_at  timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX idx_comments_task_id ON comments (task_id);
+CREATE INDEX idx_comments_deleted_at ON comments (deleted_at);
+
+CREATE TABLE comment_revisions (
+    id         uuid PRIMARY KEY,
+    comment_id uuid NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+    body       text NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX idx_comment_revisions_comment_id ON comment_revisions (comment_id);
+
+CREATE TABLE comment_mentions (
+    comment_id uuid NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (comment_id, user_id)
+);
+CREATE INDEX idx_comment_mentions_user_id ON comment_mentions (user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE attachments (
+    id           uuid PRIMARY KEY,
+    task_id      uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id      uuid NOT NULL REFERENCES users (id),
+    file_name    text NOT NULL,
+    size         bigint NOT NULL,
+    content_type text NOT NULL,
+    checksum     text NOT NULL,
+    storage_key  text NOT NULL,
+    created_at   timestamptz
+);
+CREATE INDEX idx_attachments_task_id ON attachments (task_id);
+CREATE UNIQUE INDEX idx_attachments_storage_key ON attachments (storage_key);
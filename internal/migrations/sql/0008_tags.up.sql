@@ -0,0 +1,16 @@
+CREATE TABLE tags (
+    id         uuid PRIMARY KEY,
+    name       text NOT NULL,
+    color      text,
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX idx_tags_user_name ON tags (user_id, lower(name));
+
+CREATE TABLE task_tags (
+    task_id uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    tag_id  uuid NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, tag_id)
+);
+CREATE INDEX idx_task_tags_tag_id ON task_tags (tag_id);
@@ -0,0 +1,17 @@
+CREATE TABLE task_assignees (
+    task_id    uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, user_id)
+);
+CREATE INDEX idx_task_assignees_user_id ON task_assignees (user_id);
+
+CREATE TABLE task_shares (
+    task_id    uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    permission text NOT NULL CHECK (permission IN ('view', 'edit')),
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (task_id, user_id)
+);
+CREATE INDEX idx_task_shares_user_id ON task_shares (user_id);
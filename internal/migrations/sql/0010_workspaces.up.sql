@@ -0,0 +1,51 @@
+CREATE TABLE workspaces (
+    id          uuid PRIMARY KEY,
+    name        text NOT NULL,
+    personal    boolean NOT NULL DEFAULT false,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE INDEX idx_workspaces_deleted_at ON workspaces (deleted_at);
+
+CREATE TABLE memberships (
+    workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id      uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role         text NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'guest')),
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    PRIMARY KEY (workspace_id, user_id)
+);
+CREATE INDEX idx_memberships_user_id ON memberships (user_id);
+
+CREATE TABLE invitations (
+    id            uuid PRIMARY KEY,
+    workspace_id  uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    email         text NOT NULL,
+    role          text NOT NULL CHECK (role IN ('admin', 'member', 'guest')),
+    token_hash    text NOT NULL,
+    invited_by_id uuid NOT NULL REFERENCES users (id),
+    expires_at    timestamptz NOT NULL,
+    accepted_at   timestamptz,
+    created_at    timestamptz
+);
+CREATE UNIQUE INDEX idx_invitations_token_hash ON invitations (token_hash);
+CREATE INDEX idx_invitations_workspace_id ON invitations (workspace_id);
+
+-- Every user gets a personal workspace with the same ID as the user, which
+-- holds the tasks and categories created before workspaces existed
+INSERT INTO workspaces (id, name, personal, created_at, updated_at)
+SELECT id, username, true, now(), now() FROM users;
+
+INSERT INTO memberships (workspace_id, user_id, role, created_at, updated_at)
+SELECT id, id, 'owner', now(), now() FROM users;
+
+ALTER TABLE tasks ADD COLUMN workspace_id uuid REFERENCES workspaces (id);
+UPDATE tasks SET workspace_id = user_id;
+ALTER TABLE tasks ALTER COLUMN workspace_id SET NOT NULL;
+CREATE INDEX idx_tasks_workspace_id ON tasks (workspace_id);
+
+ALTER TABLE categories ADD COLUMN workspace_id uuid REFERENCES workspaces (id);
+UPDATE categories SET workspace_id = user_id;
+ALTER TABLE categories ALTER COLUMN workspace_id SET NOT NULL;
+CREATE INDEX idx_categories_workspace_id ON categories (workspace_id);
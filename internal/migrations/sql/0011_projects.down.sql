@@ -0,0 +1,4 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS milestone_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS milestones;
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,34 @@
+CREATE TABLE projects (
+    id           uuid PRIMARY KEY,
+    name         text NOT NULL,
+    description  text,
+    status       text NOT NULL DEFAULT 'planned',
+    start_date   timestamptz,
+    target_date  timestamptz,
+    user_id      uuid NOT NULL REFERENCES users (id),
+    workspace_id uuid NOT NULL REFERENCES workspaces (id),
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz
+);
+CREATE INDEX idx_projects_user_id ON projects (user_id);
+CREATE INDEX idx_projects_workspace_id ON projects (workspace_id);
+CREATE INDEX idx_projects_deleted_at ON projects (deleted_at);
+
+CREATE TABLE milestones (
+    id          uuid PRIMARY KEY,
+    project_id  uuid NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name        text NOT NULL,
+    description text,
+    due_date    timestamptz,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE INDEX idx_milestones_project_id ON milestones (project_id);
+CREATE INDEX idx_milestones_deleted_at ON milestones (deleted_at);
+
+ALTER TABLE tasks ADD COLUMN project_id uuid REFERENCES projects (id);
+ALTER TABLE tasks ADD COLUMN milestone_id uuid REFERENCES milestones (id);
+CREATE INDEX idx_tasks_project_id ON tasks (project_id);
+CREATE INDEX idx_tasks_milestone_id ON tasks (milestone_id);
@@ -0,0 +1,4 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS rank;
+ALTER TABLE tasks DROP COLUMN IF EXISTS column_id;
+DROP TABLE IF EXISTS board_columns;
+DROP TABLE IF EXISTS boards;
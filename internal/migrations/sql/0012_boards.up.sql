@@ -0,0 +1,28 @@
+CREATE TABLE boards (
+    id           uuid PRIMARY KEY,
+    name         text NOT NULL,
+    user_id      uuid NOT NULL REFERENCES users (id),
+    workspace_id uuid NOT NULL REFERENCES workspaces (id),
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz
+);
+CREATE INDEX idx_boards_user_id ON boards (user_id);
+CREATE INDEX idx_boards_workspace_id ON boards (workspace_id);
+CREATE INDEX idx_boards_deleted_at ON boards (deleted_at);
+
+-- Ranks are compared bytewise, whatever the database's collation
+CREATE TABLE board_columns (
+    id         uuid PRIMARY KEY,
+    board_id   uuid NOT NULL REFERENCES boards (id) ON DELETE CASCADE,
+    name       text NOT NULL,
+    status     text,
+    rank       text COLLATE "C" NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX idx_board_columns_board_id_rank ON board_columns (board_id, rank);
+
+ALTER TABLE tasks ADD COLUMN column_id uuid REFERENCES board_columns (id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN rank text COLLATE "C";
+CREATE INDEX idx_tasks_column_id_rank ON tasks (column_id, rank);
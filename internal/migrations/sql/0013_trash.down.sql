@@ -0,0 +1,4 @@
+ALTER TABLE tasks DROP CONSTRAINT tasks_parent_id_fkey,
+    ADD CONSTRAINT tasks_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES tasks (id);
+ALTER TABLE tasks DROP CONSTRAINT tasks_category_id_fkey,
+    ADD CONSTRAINT tasks_category_id_fkey FOREIGN KEY (category_id) REFERENCES categories (id);
@@ -0,0 +1,24 @@
+-- Purging a trashed category or task unlinks the tasks still pointing at it.
+-- Databases adopted from GORM AutoMigrate name their keys differently, so the
+-- existing keys are looked up by column.
+DO $$
+DECLARE
+    fk record;
+BEGIN
+    FOR fk IN
+        SELECT c.conname
+        FROM pg_constraint c
+        JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = ANY (c.conkey)
+        WHERE c.conrelid = 'tasks'::regclass
+          AND c.contype = 'f'
+          AND a.attname IN ('category_id', 'parent_id')
+    LOOP
+        EXECUTE format('ALTER TABLE tasks DROP CONSTRAINT %I', fk.conname);
+    END LOOP;
+END
+$$;
+
+ALTER TABLE tasks
+    ADD CONSTRAINT tasks_category_id_fkey FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE SET NULL;
+ALTER TABLE tasks
+    ADD CONSTRAINT tasks_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES tasks (id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+-- Row versions for optimistic concurrency control, exposed as ETags
+ALTER TABLE users ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+-- Keys of anonymous requests are stored under the nil user ID, so user_id has
+-- no foreign key
+CREATE TABLE idempotency_keys (
+    user_id      uuid NOT NULL,
+    key          text NOT NULL,
+    request_hash text NOT NULL,
+    status_code  integer NOT NULL DEFAULT 0,
+    body         bytea,
+    created_at   timestamptz,
+    expires_at   timestamptz NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+COMMENT ON COLUMN idempotency_keys.user_id IS NULL;
+ALTER TABLE idempotency_keys DROP COLUMN headers;
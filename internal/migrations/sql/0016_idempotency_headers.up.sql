@@ -0,0 +1,6 @@
+-- Response headers such as ETag and Location are replayed with the body
+ALTER TABLE idempotency_keys ADD COLUMN headers jsonb;
+
+-- Keys of anonymous requests are now stored under an ID derived from the
+-- client address rather than the nil user ID; user_id still has no foreign key
+COMMENT ON COLUMN idempotency_keys.user_id IS 'user, or an ID derived from the client address for anonymous requests';
@@ -15,6 +15,8 @@ type User struct {
 	Password  string         `gorm:"not null" json:"-"`
 	FirstName string         `json:"first_name"`
 	LastName  string         `json:"last_name"`
+	Role      string         `gorm:"not null;default:'user'" json:"role"`
+	Version   int            `gorm:"not null;default:1" json:"version"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -31,6 +33,20 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// UserSummary is the part of a user that is shown to other users
+type UserSummary struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	Username  string         `json:"username"`
+	FirstName string         `json:"first_name"`
+	LastName  string         `json:"last_name"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+}
+
+// TableName reads summaries from the users table
+func (UserSummary) TableName() string {
+	return "users"
+}
+
 // Task represents a task in the system
 type Task struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
@@ -40,21 +56,144 @@ type Task struct {
 	Priority    TaskPriority   `gorm:"default:'medium'" json:"priority"`
 	DueDate     *time.Time     `json:"due_date,omitempty"`
 	UserID      uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;not null;index" json:"workspace_id"`
 	CategoryID  *uuid.UUID     `gorm:"type:uuid" json:"category_id,omitempty"`
+	ProjectID   *uuid.UUID     `gorm:"type:uuid;index" json:"project_id,omitempty"`
+	MilestoneID *uuid.UUID     `gorm:"type:uuid;index" json:"milestone_id,omitempty"`
+	ColumnID    *uuid.UUID     `gorm:"type:uuid;index" json:"column_id,omitempty"`
+	Rank        string         `json:"rank,omitempty"`
+	ParentID    *uuid.UUID     `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Recurrence  string         `json:"recurrence,omitempty"`
+	SeriesID    *uuid.UUID     `gorm:"type:uuid;index" json:"series_id,omitempty"`
+	Occurrence  int            `gorm:"default:1" json:"occurrence,omitempty"`
+	Version     int            `gorm:"not null;default:1" json:"version"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Blocked is computed from the task's dependencies and not stored
+	Blocked bool `gorm:"-" json:"blocked"`
+
 	// Relations
 	User     User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Tags     []Tag     `gorm:"many2many:task_tags" json:"tags,omitempty"`
+	// Assignees can edit the task like collaborators with edit permission
+	Assignees []User      `gorm:"many2many:task_assignees" json:"assignees,omitempty"`
+	Shares    []TaskShare `gorm:"foreignKey:TaskID" json:"shares,omitempty"`
 }
 
-// BeforeCreate will set a UUID rather than numeric ID.
+// BeforeCreate will set a UUID rather than numeric ID. Tasks created outside
+// a workspace go to their user's personal workspace.
 func (t *Task) BeforeCreate(tx *gorm.DB) error {
 	if t.ID == uuid.Nil {
 		t.ID = uuid.New()
 	}
+	if t.WorkspaceID == uuid.Nil {
+		t.WorkspaceID = t.UserID
+	}
+	return nil
+}
+
+// TaskDependency records that a task is blocked by another task of the same
+// user until that task is completed
+type TaskDependency struct {
+	TaskID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"task_id"`
+	DependsOnID uuid.UUID `gorm:"type:uuid;primaryKey" json:"depends_on_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TaskShare gives a user other than the owner access to a task
+type TaskShare struct {
+	TaskID     uuid.UUID      `gorm:"type:uuid;primaryKey" json:"task_id"`
+	UserID     uuid.UUID      `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Permission TaskPermission `gorm:"not null" json:"permission"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user"`
+}
+
+// TaskStatusChange records one status transition of a task
+type TaskStatusChange struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	TaskID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"task_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	FromStatus TaskStatus `gorm:"not null" json:"from_status"`
+	ToStatus   TaskStatus `gorm:"not null" json:"to_status"`
+	Reason     string     `json:"reason,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (c *TaskStatusChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// Comment is a message on a task. Editing keeps the previous body as a
+// CommentRevision, and users mentioned as @username are linked in Mentions.
+type Comment struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	TaskID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"task_id"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	Body      string         `gorm:"not null" json:"body"`
+	EditedAt  *time.Time     `json:"edited_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	User     User          `gorm:"foreignKey:UserID" json:"author"`
+	Mentions []UserSummary `gorm:"many2many:comment_mentions;joinForeignKey:CommentID;joinReferences:UserID" json:"mentions"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// CommentRevision is a previous body of an edited comment
+type CommentRevision struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	CommentID uuid.UUID `gorm:"type:uuid;not null;index" json:"comment_id"`
+	Body      string    `gorm:"not null" json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (r *CommentRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Attachment is the metadata of a file attached to a task. The contents
+// live in the storage backend under StorageKey.
+type Attachment struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	TaskID      uuid.UUID `gorm:"type:uuid;not null;index" json:"task_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	FileName    string    `gorm:"not null" json:"file_name"`
+	Size        int64     `gorm:"not null" json:"size"`
+	ContentType string    `gorm:"not null" json:"content_type"`
+	Checksum    string    `gorm:"not null" json:"checksum"`
+	StorageKey  string    `gorm:"not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
 	return nil
 }
 
@@ -65,6 +204,8 @@ type Category struct {
 	Description string         `json:"description"`
 	Color       string         `json:"color"`
 	UserID      uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Version     int            `gorm:"not null;default:1" json:"version"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -74,14 +215,254 @@ type Category struct {
 	Tasks []Task `gorm:"foreignKey:CategoryID" json:"tasks,omitempty"`
 }
 
-// BeforeCreate will set a UUID rather than numeric ID.
+// BeforeCreate will set a UUID rather than numeric ID. Categories created
+// outside a workspace go to their user's personal workspace.
 func (c *Category) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	if c.WorkspaceID == uuid.Nil {
+		c.WorkspaceID = c.UserID
+	}
+	return nil
+}
+
+// Project groups tasks towards a goal, above categories. Its tasks can
+// further be grouped by milestone.
+type Project struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Description string         `json:"description"`
+	Status      ProjectStatus  `gorm:"not null;default:'planned'" json:"status"`
+	StartDate   *time.Time     `json:"start_date,omitempty"`
+	TargetDate  *time.Time     `json:"target_date,omitempty"`
+	UserID      uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	Milestones []Milestone `gorm:"foreignKey:ProjectID" json:"milestones,omitempty"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID. Projects created
+// outside a workspace go to their user's personal workspace.
+func (p *Project) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	if p.WorkspaceID == uuid.Nil {
+		p.WorkspaceID = p.UserID
+	}
+	return nil
+}
+
+// Milestone is a checkpoint of a project
+type Milestone struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	ProjectID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"project_id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Description string         `json:"description"`
+	DueDate     *time.Time     `json:"due_date,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (m *Milestone) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// Board arranges tasks in user-defined columns
+type Board struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	Name        string         `gorm:"not null" json:"name"`
+	UserID      uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	Columns []BoardColumn `gorm:"foreignKey:BoardID" json:"columns,omitempty"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID. Boards created
+// outside a workspace go to their user's personal workspace.
+func (b *Board) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	if b.WorkspaceID == uuid.Nil {
+		b.WorkspaceID = b.UserID
+	}
+	return nil
+}
+
+// BoardColumn is a column of a board, ordered by rank. Tasks moved into a
+// column with a status take that status.
+type BoardColumn struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key;" json:"id"`
+	BoardID   uuid.UUID   `gorm:"type:uuid;not null;index" json:"board_id"`
+	Name      string      `gorm:"not null" json:"name"`
+	Status    *TaskStatus `json:"status,omitempty"`
+	Rank      string      `gorm:"not null" json:"rank"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+
+	// Tasks are loaded with the board, ordered by rank
+	Tasks []Task `gorm:"foreignKey:ColumnID" json:"tasks,omitempty"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (c *BoardColumn) BeforeCreate(tx *gorm.DB) error {
 	if c.ID == uuid.Nil {
 		c.ID = uuid.New()
 	}
 	return nil
 }
 
+// Workspace is a tenant that tasks and categories belong to. Every user has
+// a personal workspace with the same ID as the user; teams share the others.
+type Workspace struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Personal  bool           `gorm:"not null;default:false" json:"personal"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Role is the requesting user's role, filled in only by workspace listings
+	Role WorkspaceRole `gorm:"->;-:migration" json:"role,omitempty"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (w *Workspace) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// Membership gives a user a role in a workspace
+type Membership struct {
+	WorkspaceID uuid.UUID     `gorm:"type:uuid;primaryKey" json:"workspace_id"`
+	UserID      uuid.UUID     `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Role        WorkspaceRole `gorm:"not null" json:"role"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user"`
+}
+
+// Invitation lets whoever holds its token join a workspace with the given
+// role, either with an existing account of the invited email or by
+// registering one. Only a hash of the token is stored.
+type Invitation struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primary_key;" json:"id"`
+	WorkspaceID uuid.UUID     `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Email       string        `gorm:"not null" json:"email"`
+	Role        WorkspaceRole `gorm:"not null" json:"role"`
+	TokenHash   string        `gorm:"uniqueIndex;not null" json:"-"`
+	InvitedByID uuid.UUID     `gorm:"type:uuid;not null" json:"invited_by_id"`
+	ExpiresAt   time.Time     `gorm:"not null" json:"expires_at"`
+	AcceptedAt  *time.Time    `json:"accepted_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// Tag is a per-user label. Unlike categories, a task can carry any number of
+// tags. Names are unique per user, ignoring case.
+type Tag struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Color     string    `json:"color"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// UsageCount is the number of tasks carrying the tag, filled in only by
+	// tag listings
+	UsageCount *int64 `gorm:"->;-:migration" json:"usage_count,omitempty"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Role is a named set of permissions that can be assigned to users. The
+// built-in "user" and "admin" roles are defined in code; custom roles are
+// stored in the roles table.
+type Role struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key;" json:"id"`
+	Name        string       `gorm:"uniqueIndex;not null" json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `gorm:"serializer:json" json:"permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Built-in role names
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Permission represents an action a role allows
+type Permission string
+
+const (
+	PermissionUsersList         Permission = "users:list"
+	PermissionUsersManage       Permission = "users:manage"
+	PermissionTasksListAll      Permission = "tasks:list_all"
+	PermissionCategoriesListAll Permission = "categories:list_all"
+	PermissionRolesManage       Permission = "roles:manage"
+)
+
+// AllPermissions lists every known permission
+var AllPermissions = []Permission{
+	PermissionUsersList,
+	PermissionUsersManage,
+	PermissionTasksListAll,
+	PermissionCategoriesListAll,
+	PermissionRolesManage,
+}
+
+// IsValid checks if the permission is a known permission
+func (p Permission) IsValid() bool {
+	for _, known := range AllPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
 // TaskStatus represents the status of a task
 type TaskStatus string
 
@@ -92,6 +473,15 @@ const (
 	TaskStatusCancelled  TaskStatus = "cancelled"
 )
 
+// IsValid checks if the status is one of the known task statuses
+func (s TaskStatus) IsValid() bool {
+	switch s {
+	case TaskStatusPending, TaskStatusInProgress, TaskStatusCompleted, TaskStatusCancelled:
+		return true
+	}
+	return false
+}
+
 // TaskPriority represents the priority of a task
 type TaskPriority string
 
@@ -101,3 +491,238 @@ const (
 	TaskPriorityHigh   TaskPriority = "high"
 	TaskPriorityUrgent TaskPriority = "urgent"
 )
+
+// IsValid checks if the priority is one of the known task priorities
+func (p TaskPriority) IsValid() bool {
+	switch p {
+	case TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// TaskPermission is a level of access to a task. Each level includes the
+// ones below it: owner, then edit, then view.
+type TaskPermission string
+
+const (
+	TaskPermissionView TaskPermission = "view"
+	TaskPermissionEdit TaskPermission = "edit"
+	// TaskPermissionOwner is held by the task's user and cannot be shared
+	TaskPermissionOwner TaskPermission = "owner"
+)
+
+var taskPermissionLevels = map[TaskPermission]int{
+	TaskPermissionView:  1,
+	TaskPermissionEdit:  2,
+	TaskPermissionOwner: 3,
+}
+
+// IsShareable checks if the permission can be granted by sharing a task
+func (p TaskPermission) IsShareable() bool {
+	return p == TaskPermissionView || p == TaskPermissionEdit
+}
+
+// Includes reports whether holding p allows what other allows
+func (p TaskPermission) Includes(other TaskPermission) bool {
+	return taskPermissionLevels[p] >= taskPermissionLevels[other] && taskPermissionLevels[other] > 0
+}
+
+// CategoryDeletePolicy decides what happens to the tasks of a deleted
+// category
+type CategoryDeletePolicy string
+
+const (
+	// CategoryDeleteDetach keeps the tasks without a category
+	CategoryDeleteDetach CategoryDeletePolicy = "detach"
+	// CategoryDeleteReassign moves the tasks to another category
+	CategoryDeleteReassign CategoryDeletePolicy = "reassign"
+	// CategoryDeleteTasks deletes the tasks along with the category
+	CategoryDeleteTasks CategoryDeletePolicy = "delete"
+)
+
+// IsValid checks if the policy is one of the known category delete policies
+func (p CategoryDeletePolicy) IsValid() bool {
+	switch p {
+	case CategoryDeleteDetach, CategoryDeleteReassign, CategoryDeleteTasks:
+		return true
+	}
+	return false
+}
+
+// UserDeletePolicy decides what happens to the tasks and categories of a
+// deleted user
+type UserDeletePolicy string
+
+const (
+	// UserDeleteRestrict refuses to delete a user who still has tasks or
+	// categories
+	UserDeleteRestrict UserDeletePolicy = "restrict"
+	// UserDeleteCascade deletes the user's tasks and categories as well
+	UserDeleteCascade UserDeletePolicy = "cascade"
+)
+
+// IsValid checks if the policy is one of the known user delete policies
+func (p UserDeletePolicy) IsValid() bool {
+	return p == UserDeleteRestrict || p == UserDeleteCascade
+}
+
+// ProjectStatus represents the status of a project
+type ProjectStatus string
+
+const (
+	ProjectStatusPlanned   ProjectStatus = "planned"
+	ProjectStatusActive    ProjectStatus = "active"
+	ProjectStatusOnHold    ProjectStatus = "on_hold"
+	ProjectStatusCompleted ProjectStatus = "completed"
+	ProjectStatusCancelled ProjectStatus = "cancelled"
+)
+
+// IsValid checks if the status is one of the known project statuses
+func (s ProjectStatus) IsValid() bool {
+	switch s {
+	case ProjectStatusPlanned, ProjectStatusActive, ProjectStatusOnHold, ProjectStatusCompleted, ProjectStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// WorkspaceRole is a member's role in a workspace. Owners and admins manage
+// the workspace and its members, members work in it, and guests only see the
+// tasks shared with or assigned to them.
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+	WorkspaceRoleAdmin  WorkspaceRole = "admin"
+	WorkspaceRoleMember WorkspaceRole = "member"
+	WorkspaceRoleGuest  WorkspaceRole = "guest"
+)
+
+// IsValid checks if the role is one of the known workspace roles
+func (r WorkspaceRole) IsValid() bool {
+	switch r {
+	case WorkspaceRoleOwner, WorkspaceRoleAdmin, WorkspaceRoleMember, WorkspaceRoleGuest:
+		return true
+	}
+	return false
+}
+
+// CanManage reports whether the role may manage the workspace and its members
+func (r WorkspaceRole) CanManage() bool {
+	return r == WorkspaceRoleOwner || r == WorkspaceRoleAdmin
+}
+
+// CanContribute reports whether the role may create tasks and categories
+func (r WorkspaceRole) CanContribute() bool {
+	return r.CanManage() || r == WorkspaceRoleMember
+}
+
+// TaskSortField is a field tasks can be ordered by
+type TaskSortField string
+
+const (
+	TaskSortDueDate   TaskSortField = "due_date"
+	TaskSortPriority  TaskSortField = "priority"
+	TaskSortCreatedAt TaskSortField = "created_at"
+	TaskSortUpdatedAt TaskSortField = "updated_at"
+	TaskSortTitle     TaskSortField = "title"
+)
+
+// IsValid checks if tasks can be sorted by the field
+func (f TaskSortField) IsValid() bool {
+	switch f {
+	case TaskSortDueDate, TaskSortPriority, TaskSortCreatedAt, TaskSortUpdatedAt, TaskSortTitle:
+		return true
+	}
+	return false
+}
+
+// TaskFilter selects and orders tasks. Zero fields do not filter; all set
+// fields must match.
+type TaskFilter struct {
+	UserID        *uuid.UUID
+	AssigneeID    *uuid.UUID
+	Statuses      []TaskStatus
+	Priorities    []TaskPriority
+	CategoryID    *uuid.UUID
+	DueBefore     *time.Time
+	DueAfter      *time.Time
+	HasDueDate    *bool
+	Overdue       bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	ProjectID     *uuid.UUID
+	MilestoneID   *uuid.UUID
+	// TagIDs matches tasks carrying any of the tags, or all of them when
+	// AllTags is set
+	TagIDs  []uuid.UUID
+	AllTags bool
+	// Query matches title or description, case-insensitively
+	Query    string
+	SortBy   TaskSortField
+	SortDesc bool
+}
+
+// Page sizes of cursor-paginated lists
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// PageRequest selects one page of a cursor-paginated list. An empty cursor
+// starts at the first page.
+type PageRequest struct {
+	Cursor    string
+	Limit     int
+	WithTotal bool
+}
+
+// Page is one page of a list with the opaque cursor of the next page. Total
+// is only counted when requested.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// RefreshToken represents an issued refresh token. Only a hash of the token
+// is stored; tokens issued by rotation share the FamilyID of the login that
+// started the chain so a replayed token can revoke the whole chain.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"family_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (r *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// IdempotencyKey stores the response to the first request sent with an
+// Idempotency-Key header so that retries get the same response. Keys are
+// scoped to the authenticated user, or to uuid.Nil for anonymous requests.
+type IdempotencyKey struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Key    string    `gorm:"primaryKey"`
+	// RequestHash fingerprints the request so a reused key can be detected
+	RequestHash string `gorm:"not null"`
+	// StatusCode is zero while the first request is still being handled
+	StatusCode int
+	// Headers are the response headers replayed along with the body
+	Headers   map[string]string `gorm:"serializer:json"`
+	Body      []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,70 @@
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// Ranks are lexicographic sort keys that always leave room for another key
+// between any two, so an item is moved by rewriting only its own key instead
+// of renumbering its neighbours. alphabet lists their digits in ascending byte
+// order; ranks must be compared bytewise (COLLATE "C" in Postgres).
+const alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// ErrInvalidRange is returned when there is no rank between the bounds
+var ErrInvalidRange = errors.New("no rank between the bounds")
+
+// Between returns a rank that sorts after prev and before next. An empty prev
+// means the start of the list and an empty next its end. Generated ranks never
+// end in the lowest digit, so there is always room before them as well.
+func Between(prev, next string) (string, error) {
+	if next != "" && prev >= next {
+		return "", ErrInvalidRange
+	}
+	if !valid(prev) || !valid(next) {
+		return "", ErrInvalidRange
+	}
+
+	var out []byte
+	// bounded is true while out is still a prefix of next
+	bounded := next != ""
+	for i := 0; ; i++ {
+		low := 0
+		if i < len(prev) {
+			low = strings.IndexByte(alphabet, prev[i])
+		}
+		high := len(alphabet)
+		if bounded {
+			if i >= len(next) {
+				return "", ErrInvalidRange
+			}
+			high = strings.IndexByte(alphabet, next[i])
+		}
+
+		if high-low >= 2 {
+			return string(append(out, alphabet[(low+high)/2])), nil
+		}
+
+		// No digit fits strictly between; keep the lower one and look one
+		// digit further
+		out = append(out, alphabet[low])
+		if high > low {
+			bounded = false
+		}
+	}
+}
+
+// After returns a rank that sorts after prev, for appending to a list
+func After(prev string) (string, error) {
+	return Between(prev, "")
+}
+
+// valid reports whether the rank only uses digits of the alphabet
+func valid(rank string) bool {
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(alphabet, rank[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
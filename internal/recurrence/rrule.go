@@ -0,0 +1,270 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base period of a recurrence rule
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// maxInterval bounds INTERVAL, so a rule cannot push its next occurrence
+// centuries away
+const maxInterval = 366
+
+// untilLayouts are the DATE and DATE-TIME forms accepted for UNTIL
+var untilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+
+// Rule is the supported subset of an RFC 5545 RRULE: FREQ, INTERVAL, BYDAY,
+// BYMONTHDAY, UNTIL and COUNT
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Until      *time.Time
+	Count      int
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR".
+// A leading "RRULE:" is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, errors.New("recurrence rule is empty")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+		name = strings.ToUpper(name)
+		if seen[name] {
+			return nil, fmt.Errorf("recurrence rule part %s given twice", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(val))
+			switch rule.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 || interval > maxInterval {
+				return nil, fmt.Errorf("invalid INTERVAL %q, use 1 to %d", val, maxInterval)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekday, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY value %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY value %q", day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+			rule.Count = count
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %s", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("recurrence rule requires FREQ")
+	}
+	if rule.Until != nil && rule.Count > 0 {
+		return nil, errors.New("recurrence rule cannot have both UNTIL and COUNT")
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range untilLayouts {
+		if until, err := time.Parse(layout, value); err == nil {
+			// A bare date includes the whole day
+			if layout == "20060102" {
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+}
+
+// Next returns the first occurrence after current, where current is the
+// occurrence with the given 1-based number in the series. The time of day of
+// current is kept. It returns false once the series is exhausted.
+func (r *Rule) Next(current time.Time, occurrence int) (time.Time, bool) {
+	if r.Count > 0 && occurrence >= r.Count {
+		return time.Time{}, false
+	}
+
+	// Periods are counted from current, which always lies in a valid period.
+	// Periods skipped by INTERVAL are jumped over and the others walked day
+	// by day, which keeps the BYxxx filters simple; eight periods is enough
+	// to reach the next Feb 29 of a yearly rule.
+	limit := current.AddDate(8*r.Interval, 0, 0)
+	if r.Freq == Monthly {
+		limit = current.AddDate(0, 8*r.Interval, 0)
+	}
+	candidate := current.AddDate(0, 0, 1)
+	for !candidate.After(limit) {
+		if r.Until != nil && candidate.After(*r.Until) {
+			return time.Time{}, false
+		}
+		if skipped := r.periods(current, candidate) % r.Interval; skipped != 0 {
+			candidate = r.periodStart(candidate, r.Interval-skipped)
+			continue
+		}
+		if r.matches(current, candidate) {
+			return candidate, true
+		}
+		candidate = candidate.AddDate(0, 0, 1)
+	}
+	return time.Time{}, false
+}
+
+// Repeats reports whether the rule has an occurrence after start at all,
+// whatever its COUNT and UNTIL. It does not when its BYxxx parts never fall
+// in a period selected by INTERVAL, such as BYMONTHDAY=30 every 12 months
+// from February.
+func (r *Rule) Repeats(start time.Time) bool {
+	unbounded := *r
+	unbounded.Count = 0
+	unbounded.Until = nil
+	_, ok := unbounded.Next(start, 1)
+	return ok
+}
+
+// periods returns the number of whole periods from start to candidate
+func (r *Rule) periods(start, candidate time.Time) int {
+	switch r.Freq {
+	case Weekly:
+		// Weeks start on Monday (WKST=MO)
+		return daysBetween(weekStart(start), weekStart(candidate)) / 7
+	case Monthly:
+		return (candidate.Year()-start.Year())*12 + int(candidate.Month()-start.Month())
+	case Yearly:
+		return candidate.Year() - start.Year()
+	default:
+		return daysBetween(start, candidate)
+	}
+}
+
+// periodStart returns the first day of the period n periods after the one
+// of t, at the time of day of t
+func (r *Rule) periodStart(t time.Time, n int) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return weekStart(t).AddDate(0, 0, 7*n)
+	case Monthly:
+		return time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	case Yearly:
+		return time.Date(t.Year()+n, time.January, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// matches applies BYDAY and BYMONTHDAY, falling back to the weekday, day of
+// month or date of start when the rule does not narrow the period itself
+func (r *Rule) matches(start, candidate time.Time) bool {
+	if len(r.ByDay) > 0 && !containsWeekday(r.ByDay, candidate.Weekday()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !containsMonthDay(r.ByMonthDay, candidate) {
+		return false
+	}
+	if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+		return true
+	}
+
+	switch r.Freq {
+	case Weekly:
+		return candidate.Weekday() == start.Weekday()
+	case Monthly:
+		return candidate.Day() == start.Day()
+	case Yearly:
+		return candidate.Month() == start.Month() && candidate.Day() == start.Day()
+	default:
+		return true
+	}
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// containsMonthDay matches positive days from the start of the month and
+// negative days from its end, so -1 is the last day
+func containsMonthDay(days []int, t time.Time) bool {
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, d := range days {
+		if d == t.Day() || (d < 0 && last+d+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func daysBetween(a, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
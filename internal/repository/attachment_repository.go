@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *model.Attachment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Attachment, error)
+	ListByTask(ctx context.Context, taskID uuid.UUID) ([]model.Attachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *model.Attachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := r.db.WithContext(ctx).First(&attachment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListByTask returns the task's attachments, oldest first
+func (r *attachmentRepository) ListByTask(ctx context.Context, taskID uuid.UUID) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at").Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Attachment{}, "id = ?", id).Error
+}
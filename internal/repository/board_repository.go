@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/rank"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidPosition is returned when a task is moved after a task that is
+// not in the target column
+var ErrInvalidPosition = errors.New("task to move after is not in the column")
+
+type BoardRepository interface {
+	Create(ctx context.Context, board *model.Board) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Board, error)
+	GetWithTasks(ctx context.Context, id uuid.UUID) (*model.Board, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Board, error)
+	Update(ctx context.Context, board *model.Board) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	CreateColumn(ctx context.Context, column *model.BoardColumn) error
+	GetColumn(ctx context.Context, boardID, id uuid.UUID) (*model.BoardColumn, error)
+	FindColumn(ctx context.Context, id uuid.UUID) (*model.BoardColumn, error)
+	UpdateColumn(ctx context.Context, column *model.BoardColumn) error
+	DeleteColumn(ctx context.Context, boardID, id uuid.UUID) (bool, error)
+	MoveTask(ctx context.Context, taskID uuid.UUID, version int, columnID uuid.UUID, afterID *uuid.UUID, status *model.TaskStatus) (string, error)
+	// InWorkspace returns a repository whose queries only see the boards of
+	// the workspace and which creates boards in it
+	InWorkspace(workspaceID uuid.UUID) BoardRepository
+}
+
+type boardRepository struct {
+	db          *gorm.DB
+	workspaceID *uuid.UUID
+}
+
+// NewBoardRepository creates a repository over the boards of all
+// workspaces. Requests on behalf of a user go through InWorkspace.
+func NewBoardRepository(db *gorm.DB) BoardRepository {
+	return &boardRepository{db: db}
+}
+
+func (r *boardRepository) InWorkspace(workspaceID uuid.UUID) BoardRepository {
+	return &boardRepository{db: r.db, workspaceID: &workspaceID}
+}
+
+// scoped starts a query limited to the repository's workspace
+func (r *boardRepository) scoped(ctx context.Context) *gorm.DB {
+	return r.scopedTx(r.db.WithContext(ctx))
+}
+
+// scopedTx is scoped for a transaction
+func (r *boardRepository) scopedTx(tx *gorm.DB) *gorm.DB {
+	if r.workspaceID == nil {
+		return tx
+	}
+	return tx.Where("workspace_id = ?", *r.workspaceID)
+}
+
+// Create creates the board together with its columns, ranked in the order
+// given
+func (r *boardRepository) Create(ctx context.Context, board *model.Board) error {
+	if r.workspaceID != nil {
+		board.WorkspaceID = *r.workspaceID
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(board).Error; err != nil {
+			return err
+		}
+		last := ""
+		for i := range board.Columns {
+			column := &board.Columns[i]
+			column.BoardID = board.ID
+			next, err := rank.After(last)
+			if err != nil {
+				return err
+			}
+			column.Rank = next
+			if err := tx.Omit(clause.Associations).Create(column).Error; err != nil {
+				return err
+			}
+			last = next
+		}
+		return nil
+	})
+}
+
+// GetByID returns the board with its columns in order
+func (r *boardRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Board, error) {
+	var board model.Board
+	err := r.scoped(ctx).Preload("Columns", func(db *gorm.DB) *gorm.DB {
+		return db.Order("rank, created_at")
+	}).First(&board, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// GetWithTasks returns the board with its columns and their tasks in order
+func (r *boardRepository) GetWithTasks(ctx context.Context, id uuid.UUID) (*model.Board, error) {
+	var board model.Board
+	err := r.scoped(ctx).Preload("Columns", func(db *gorm.DB) *gorm.DB {
+		return db.Order("rank, created_at")
+	}).Preload("Columns.Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("rank, id")
+	}).First(&board, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+func (r *boardRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Board, error) {
+	var boards []model.Board
+	err := r.scoped(ctx).Where("user_id = ?", userID).Order("created_at").Find(&boards).Error
+	return boards, err
+}
+
+func (r *boardRepository) Update(ctx context.Context, board *model.Board) error {
+	// Columns are changed through their own methods. Selecting the columns
+	// keeps Save from inserting the board when it is outside the workspace.
+	return r.scoped(ctx).Select("*").Omit(clause.Associations).Save(board).Error
+}
+
+// Delete deletes the board (soft delete) and its columns, taking its tasks
+// off the board
+func (r *boardRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := r.scopedTx(tx).Delete(&model.Board{}, "id = ?", id)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		columns := tx.Model(&model.BoardColumn{}).Select("id").Where("board_id = ?", id)
+		err := tx.Model(&model.Task{}).Where("column_id IN (?)", columns).
+			Updates(map[string]any{"column_id": nil, "rank": nil}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.BoardColumn{}, "board_id = ?", id).Error
+	})
+}
+
+// CreateColumn adds a column after the last column of its board
+func (r *boardRepository) CreateColumn(ctx context.Context, column *model.BoardColumn) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the board keeps concurrent additions from sharing a rank
+		var board model.Board
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&board, "id = ?", column.BoardID).Error; err != nil {
+			return err
+		}
+
+		var ranks []string
+		err := tx.Model(&model.BoardColumn{}).Where("board_id = ?", column.BoardID).
+			Order("rank DESC").Limit(1).Pluck("rank", &ranks).Error
+		if err != nil {
+			return err
+		}
+		last := ""
+		if len(ranks) > 0 {
+			last = ranks[0]
+		}
+
+		next, err := rank.After(last)
+		if err != nil {
+			return err
+		}
+		column.Rank = next
+		return tx.Omit(clause.Associations).Create(column).Error
+	})
+}
+
+func (r *boardRepository) GetColumn(ctx context.Context, boardID, id uuid.UUID) (*model.BoardColumn, error) {
+	var column model.BoardColumn
+	err := r.db.WithContext(ctx).First(&column, "board_id = ? AND id = ?", boardID, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &column, nil
+}
+
+// FindColumn looks a column up by its ID alone
+func (r *boardRepository) FindColumn(ctx context.Context, id uuid.UUID) (*model.BoardColumn, error) {
+	var column model.BoardColumn
+	err := r.db.WithContext(ctx).First(&column, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &column, nil
+}
+
+func (r *boardRepository) UpdateColumn(ctx context.Context, column *model.BoardColumn) error {
+	return r.db.WithContext(ctx).Select("*").Omit(clause.Associations).Save(column).Error
+}
+
+// DeleteColumn deletes a column, taking its tasks off the board, and reports
+// whether it existed
+func (r *boardRepository) DeleteColumn(ctx context.Context, boardID, id uuid.UUID) (bool, error) {
+	var removed bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var column model.BoardColumn
+		err := tx.First(&column, "board_id = ? AND id = ?", boardID, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		removed = true
+
+		err = tx.Model(&model.Task{}).Where("column_id = ?", id).
+			Updates(map[string]any{"column_id": nil, "rank": nil}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&column).Error
+	})
+	return removed, err
+}
+
+// MoveTask places the task in the column right after the task afterID, or at
+// the top when afterID is nil, and sets its status when one is given. Only
+// the moved task's row is written; it gets a rank between its new
+// neighbours, which is returned. The move fails with ErrVersionConflict when
+// the task is no longer at the version it was loaded at.
+func (r *boardRepository) MoveTask(ctx context.Context, taskID uuid.UUID, version int, columnID uuid.UUID, afterID *uuid.UUID, status *model.TaskStatus) (string, error) {
+	var newRank string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the column serializes moves into it, so two tasks
+		// dropped at the same place do not get the same rank
+		var column model.BoardColumn
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&column, "id = ?", columnID).Error; err != nil {
+			return err
+		}
+
+		prev := ""
+		if afterID != nil {
+			var ranks []string
+			err := tx.Model(&model.Task{}).Where("id = ? AND column_id = ?", *afterID, columnID).
+				Pluck("rank", &ranks).Error
+			if err != nil {
+				return err
+			}
+			if len(ranks) == 0 {
+				return ErrInvalidPosition
+			}
+			prev = ranks[0]
+		}
+
+		var ranks []string
+		err := tx.Model(&model.Task{}).Where("column_id = ? AND id <> ? AND rank > ?", columnID, taskID, prev).
+			Order("rank").Limit(1).Pluck("rank", &ranks).Error
+		if err != nil {
+			return err
+		}
+		next := ""
+		if len(ranks) > 0 {
+			next = ranks[0]
+		}
+
+		newRank, err = rank.Between(prev, next)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{"column_id": columnID, "rank": newRank, "version": gorm.Expr("version + 1")}
+		if status != nil {
+			updates["status"] = *status
+		}
+		result := tx.Model(&model.Task{}).Where("id = ? AND version = ?", taskID, version).Updates(updates)
+		if result.Error == nil && result.RowsAffected == 0 {
+			result.Error = ErrVersionConflict
+		}
+		return result.Error
+	})
+	return newRank, err
+}
@@ -2,57 +2,111 @@ package repository
 
 import (
 	"Arise-test/internal/model"
+	"context"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type CategoryRepository interface {
-	Create(category *model.Category) error
-	GetByID(id uuid.UUID) (*model.Category, error)
-	GetByUserID(userID uuid.UUID) ([]model.Category, error)
-	Update(category *model.Category) error
-	Delete(id uuid.UUID) error
-	List(limit, offset int) ([]model.Category, error)
+	Create(ctx context.Context, category *model.Category) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Category, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Category, error)
+	Update(ctx context.Context, category *model.Category) error
+	// Delete soft deletes the category, only at the version when one is
+	// given
+	Delete(ctx context.Context, id uuid.UUID, version *int) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	List(ctx context.Context, page model.PageRequest) (model.Page[model.Category], error)
+	// InWorkspace returns a repository whose queries only see the
+	// categories of the workspace and which creates categories in it
+	InWorkspace(workspaceID uuid.UUID) CategoryRepository
 }
 
 type categoryRepository struct {
-	db *gorm.DB
+	db          *gorm.DB
+	workspaceID *uuid.UUID
 }
 
+// NewCategoryRepository creates a repository over the categories of all
+// workspaces. Requests on behalf of a user go through InWorkspace.
 func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
-func (r *categoryRepository) Create(category *model.Category) error {
-	return r.db.Create(category).Error
+func (r *categoryRepository) InWorkspace(workspaceID uuid.UUID) CategoryRepository {
+	return &categoryRepository{db: r.db, workspaceID: &workspaceID}
 }
 
-func (r *categoryRepository) GetByID(id uuid.UUID) (*model.Category, error) {
+// scoped starts a query limited to the repository's workspace
+func (r *categoryRepository) scoped(ctx context.Context) *gorm.DB {
+	if r.workspaceID == nil {
+		return r.db.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx).Where("workspace_id = ?", *r.workspaceID)
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *model.Category) error {
+	if r.workspaceID != nil {
+		category.WorkspaceID = *r.workspaceID
+	}
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
 	var category model.Category
-	err := r.db.Preload("Tasks").First(&category, "id = ?", id).Error
+	err := r.scoped(ctx).Preload("Tasks").First(&category, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &category, nil
 }
 
-func (r *categoryRepository) GetByUserID(userID uuid.UUID) ([]model.Category, error) {
+func (r *categoryRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Category, error) {
 	var categories []model.Category
-	err := r.db.Where("user_id = ?", userID).Find(&categories).Error
+	err := r.scoped(ctx).Where("user_id = ?", userID).Find(&categories).Error
 	return categories, err
 }
 
-func (r *categoryRepository) Update(category *model.Category) error {
-	return r.db.Save(category).Error
+// Update saves the category and bumps its version, failing with
+// ErrVersionConflict when it changed or left the workspace since it was
+// loaded
+func (r *categoryRepository) Update(ctx context.Context, category *model.Category) error {
+	return updateVersioned(r.scoped(ctx), category, &category.Version)
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID, version *int) error {
+	return deleteVersioned(r.scoped(ctx), &model.Category{}, id, version)
 }
 
-func (r *categoryRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&model.Category{}, "id = ?", id).Error
+// DeleteByUserID deletes all of the user's categories (soft delete)
+func (r *categoryRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.scoped(ctx).Delete(&model.Category{}, "user_id = ?", userID).Error
 }
 
-func (r *categoryRepository) List(limit, offset int) ([]model.Category, error) {
+// List returns a page of all categories, oldest first
+func (r *categoryRepository) List(ctx context.Context, page model.PageRequest) (model.Page[model.Category], error) {
+	query := r.scoped(ctx).Model(&model.Category{}).Session(&gorm.Session{})
+
+	var result model.Page[model.Category]
+	total, err := countTotal(query, page)
+	if err != nil {
+		return result, err
+	}
+
+	paged, err := paginate(query, createdAtKey, page)
+	if err != nil {
+		return result, err
+	}
+
 	var categories []model.Category
-	err := r.db.Limit(limit).Offset(offset).Find(&categories).Error
-	return categories, err
+	if err := paged.Find(&categories).Error; err != nil {
+		return result, err
+	}
+
+	result = buildPage(categories, createdAtKey, page, func(c *model.Category) (any, uuid.UUID) {
+		return c.CreatedAt, c.ID
+	})
+	result.Total = total
+	return result, nil
 }
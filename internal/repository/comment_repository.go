@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *model.Comment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Comment, error)
+	ListByTask(ctx context.Context, taskID uuid.UUID, page model.PageRequest) (model.Page[model.Comment], error)
+	Update(ctx context.Context, comment *model.Comment, revision *model.CommentRevision) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetRevisions(ctx context.Context, commentID uuid.UUID) ([]model.CommentRevision, error)
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+// Create stores the comment and links its mentioned users
+func (r *commentRepository) Create(ctx context.Context, comment *model.Comment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(comment).Error; err != nil {
+			return err
+		}
+		return replaceMentions(tx, comment)
+	})
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Comment, error) {
+	var comment model.Comment
+	err := r.db.WithContext(ctx).Preload("User").Preload("Mentions").First(&comment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListByTask returns a page of the task's comments, oldest first
+func (r *commentRepository) ListByTask(ctx context.Context, taskID uuid.UUID, page model.PageRequest) (model.Page[model.Comment], error) {
+	query := r.db.WithContext(ctx).Model(&model.Comment{}).Where("task_id = ?", taskID).Session(&gorm.Session{})
+
+	var result model.Page[model.Comment]
+	total, err := countTotal(query, page)
+	if err != nil {
+		return result, err
+	}
+
+	paged, err := paginate(query.Preload("User").Preload("Mentions"), createdAtKey, page)
+	if err != nil {
+		return result, err
+	}
+
+	var comments []model.Comment
+	if err := paged.Find(&comments).Error; err != nil {
+		return result, err
+	}
+
+	result = buildPage(comments, createdAtKey, page, func(c *model.Comment) (any, uuid.UUID) {
+		return c.CreatedAt, c.ID
+	})
+	result.Total = total
+	return result, nil
+}
+
+// Update saves an edited comment together with the revision holding its
+// previous body, and relinks its mentioned users
+func (r *commentRepository) Update(ctx context.Context, comment *model.Comment, revision *model.CommentRevision) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(comment).Error; err != nil {
+			return err
+		}
+		return replaceMentions(tx, comment)
+	})
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Comment{}, "id = ?", id).Error
+}
+
+// GetRevisions returns the previous bodies of a comment, oldest first
+func (r *commentRepository) GetRevisions(ctx context.Context, commentID uuid.UUID) ([]model.CommentRevision, error) {
+	var revisions []model.CommentRevision
+	err := r.db.WithContext(ctx).Where("comment_id = ?", commentID).Order("created_at").Find(&revisions).Error
+	return revisions, err
+}
+
+// replaceMentions writes the comment_mentions rows directly so that the
+// mentioned users themselves are never saved through the association
+func replaceMentions(tx *gorm.DB, comment *model.Comment) error {
+	if err := tx.Exec("DELETE FROM comment_mentions WHERE comment_id = ?", comment.ID).Error; err != nil {
+		return err
+	}
+	for _, user := range comment.Mentions {
+		err := tx.Exec("INSERT INTO comment_mentions (comment_id, user_id) VALUES (?, ?)", comment.ID, user.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
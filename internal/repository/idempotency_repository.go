@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *model.IdempotencyKey) (bool, error)
+	Get(ctx context.Context, userID uuid.UUID, key string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, headers map[string]string, body []byte) error
+	Delete(ctx context.Context, userID uuid.UUID, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve stores the record unless its key is already taken. It reports
+// whether the record was stored, so of two concurrent requests with the same
+// key only one gets to handle it.
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *model.IdempotencyKey) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, userID uuid.UUID, key string) (*model.IdempotencyKey, error) {
+	var record model.IdempotencyKey
+	err := r.db.WithContext(ctx).First(&record, "user_id = ? AND key = ?", userID, key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Complete stores the response of the request that reserved the key
+func (r *idempotencyRepository) Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, headers map[string]string, body []byte) error {
+	response := &model.IdempotencyKey{StatusCode: statusCode, Headers: headers, Body: body}
+	return r.db.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Select("status_code", "headers", "body").Updates(response).Error
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, userID uuid.UUID, key string) error {
+	return r.db.WithContext(ctx).Delete(&model.IdempotencyKey{}, "user_id = ? AND key = ?", userID, key).Error
+}
+
+// DeleteExpired deletes the keys that expired before now and returns how
+// many there were
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&model.IdempotencyKey{}, "expires_at < ?", now)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *model.Invitation) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error)
+	LockByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error)
+	GetPending(ctx context.Context, workspaceID uuid.UUID) ([]model.Invitation, error)
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) (bool, error)
+	DeletePendingByUser(ctx context.Context, userID uuid.UUID, email string) error
+	Accept(ctx context.Context, invitation *model.Invitation, userID uuid.UUID) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *model.Invitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+func (r *invitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.WithContext(ctx).First(&invitation, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// LockByTokenHash loads an invitation and locks its row until the
+// surrounding transaction ends, so that it can only be used once
+func (r *invitationRepository) LockByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&invitation, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPending returns the invitations of a workspace that can still be
+// accepted, newest first
+func (r *invitationRepository) GetPending(ctx context.Context, workspaceID uuid.UUID) ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	err := r.db.WithContext(ctx).Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceID, time.Now()).
+		Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// Delete revokes an invitation and reports whether it existed
+func (r *invitationRepository) Delete(ctx context.Context, workspaceID, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&model.Invitation{}, "workspace_id = ? AND id = ?", workspaceID, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeletePendingByUser revokes the invitations that were sent by the user or
+// to their email address and have not been accepted
+func (r *invitationRepository) DeletePendingByUser(ctx context.Context, userID uuid.UUID, email string) error {
+	return r.db.WithContext(ctx).
+		Where("accepted_at IS NULL AND (invited_by_id = ? OR email = ?)", userID, strings.ToLower(email)).
+		Delete(&model.Invitation{}).Error
+}
+
+// Accept marks the invitation as used and adds the user to its workspace.
+// Users who already are members keep their role. An invitation that was
+// accepted in the meantime yields gorm.ErrRecordNotFound.
+func (r *invitationRepository) Accept(ctx context.Context, invitation *model.Invitation, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		invitation.AcceptedAt = &now
+
+		return tx.Exec(`
+			INSERT INTO memberships (workspace_id, user_id, role, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING`, invitation.WorkspaceID, userID, invitation.Role, now, now).Error
+	})
+}
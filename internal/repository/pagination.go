@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type valueKind int
+
+const (
+	kindTime valueKind = iota
+	kindInt
+	kindString
+)
+
+// sortKey describes the ordering of a keyset-paginated list. Rows are
+// ordered by expr, with NULLs last, then by id ascending so every row has a
+// unique position.
+type sortKey struct {
+	name     string
+	expr     string
+	kind     valueKind
+	nullable bool
+	desc     bool
+}
+
+// createdAtKey is the ordering of lists without a choice of sort
+var createdAtKey = sortKey{name: "created_at", expr: "created_at", kind: kindTime}
+
+// cursor is the position after the last row of a page. Sort identifies the
+// ordering it belongs to, so a cursor cannot be reused with another sort.
+type cursor struct {
+	Sort  string    `json:"s"`
+	Value *string   `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// pageLimit clamps the requested page size
+func pageLimit(page model.PageRequest) int {
+	switch {
+	case page.Limit <= 0:
+		return model.DefaultPageSize
+	case page.Limit > model.MaxPageSize:
+		return model.MaxPageSize
+	default:
+		return page.Limit
+	}
+}
+
+// paginate orders the query by key, skips past the page cursor and fetches
+// one row more than the page size to tell whether another page follows
+func paginate(query *gorm.DB, key sortKey, page model.PageRequest) (*gorm.DB, error) {
+	direction, after := " ASC", " > "
+	if key.desc {
+		direction, after = " DESC", " < "
+	}
+
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil || c.Sort != key.sortName() {
+			return nil, ErrInvalidCursor
+		}
+
+		if c.Value == nil {
+			if !key.nullable {
+				return nil, ErrInvalidCursor
+			}
+			query = query.Where(key.expr+" IS NULL AND id > ?", c.ID)
+		} else {
+			value, err := key.parse(*c.Value)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			condition := "(" + key.expr + after + "? OR (" + key.expr + " = ? AND id > ?)"
+			if key.nullable {
+				condition += " OR " + key.expr + " IS NULL"
+			}
+			query = query.Where(condition+")", value, value, c.ID)
+		}
+	}
+
+	return query.Order(key.expr + direction + " NULLS LAST").Order("id").Limit(pageLimit(page) + 1), nil
+}
+
+// buildPage trims the extra row fetched by paginate and derives the next
+// cursor from the last row of the page. position returns the sort value and
+// id of a row, with a nil value for NULL.
+func buildPage[T any](items []T, key sortKey, page model.PageRequest, position func(*T) (any, uuid.UUID)) model.Page[T] {
+	limit := pageLimit(page)
+	result := model.Page[T]{Items: items}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+
+	if len(items) > limit {
+		result.Items = items[:limit]
+		result.HasMore = true
+
+		value, id := position(&result.Items[limit-1])
+		result.NextCursor = encodeCursor(cursor{Sort: key.sortName(), Value: formatSortValue(value), ID: id})
+	}
+	return result
+}
+
+// countTotal counts the rows of a filtered query when the page asks for it
+func countTotal(query *gorm.DB, page model.PageRequest) (*int64, error) {
+	if !page.WithTotal {
+		return nil, nil
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+func (k sortKey) sortName() string {
+	if k.desc {
+		return k.name + ":desc"
+	}
+	return k.name
+}
+
+// formatSortValue renders a sort value for a cursor, with nil for NULL
+func formatSortValue(value any) *string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		s = v.UTC().Format(time.RFC3339Nano)
+	case time.Time:
+		s = v.UTC().Format(time.RFC3339Nano)
+	case int:
+		s = strconv.Itoa(v)
+	case string:
+		s = v
+	}
+	return &s
+}
+
+func (k sortKey) parse(value string) (any, error) {
+	switch k.kind {
+	case kindTime:
+		return time.Parse(time.RFC3339Nano, value)
+	case kindInt:
+		return strconv.Atoi(value)
+	default:
+		return value, nil
+	}
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskStatusCount is the number of a project's tasks with one status in one
+// milestone, or outside any milestone when MilestoneID is nil
+type TaskStatusCount struct {
+	MilestoneID *uuid.UUID
+	Status      model.TaskStatus
+	Count       int64
+}
+
+type ProjectRepository interface {
+	Create(ctx context.Context, project *model.Project) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Project, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Project, error)
+	Update(ctx context.Context, project *model.Project) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	CreateMilestone(ctx context.Context, milestone *model.Milestone) error
+	GetMilestone(ctx context.Context, projectID, id uuid.UUID) (*model.Milestone, error)
+	FindMilestone(ctx context.Context, id uuid.UUID) (*model.Milestone, error)
+	UpdateMilestone(ctx context.Context, milestone *model.Milestone) error
+	DeleteMilestone(ctx context.Context, projectID, id uuid.UUID) (bool, error)
+	CountTasks(ctx context.Context, projectID uuid.UUID) ([]TaskStatusCount, error)
+	// InWorkspace returns a repository whose queries only see the projects
+	// of the workspace and which creates projects in it
+	InWorkspace(workspaceID uuid.UUID) ProjectRepository
+}
+
+type projectRepository struct {
+	db          *gorm.DB
+	workspaceID *uuid.UUID
+}
+
+// NewProjectRepository creates a repository over the projects of all
+// workspaces. Requests on behalf of a user go through InWorkspace.
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+func (r *projectRepository) InWorkspace(workspaceID uuid.UUID) ProjectRepository {
+	return &projectRepository{db: r.db, workspaceID: &workspaceID}
+}
+
+// scoped starts a query limited to the repository's workspace
+func (r *projectRepository) scoped(ctx context.Context) *gorm.DB {
+	if r.workspaceID == nil {
+		return r.db.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx).Where("workspace_id = ?", *r.workspaceID)
+}
+
+func (r *projectRepository) Create(ctx context.Context, project *model.Project) error {
+	if r.workspaceID != nil {
+		project.WorkspaceID = *r.workspaceID
+	}
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(project).Error
+}
+
+// GetByID returns the project with its milestones, soonest due first
+func (r *projectRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Project, error) {
+	var project model.Project
+	err := r.scoped(ctx).Preload("Milestones", func(db *gorm.DB) *gorm.DB {
+		return db.Order("due_date NULLS LAST, created_at")
+	}).First(&project, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Project, error) {
+	var projects []model.Project
+	err := r.scoped(ctx).Where("user_id = ?", userID).Order("created_at").Find(&projects).Error
+	return projects, err
+}
+
+func (r *projectRepository) Update(ctx context.Context, project *model.Project) error {
+	// Preloaded milestones are read-only views; only the row itself is
+	// saved. Selecting the columns keeps Save from inserting the project
+	// when it is outside the workspace.
+	return r.scoped(ctx).Select("*").Omit(clause.Associations).Save(project).Error
+}
+
+// Delete deletes the project with its milestones (soft delete), taking its
+// tasks out of the project
+func (r *projectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := r.scopedTx(tx).Delete(&model.Project{}, "id = ?", id)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		err := tx.Model(&model.Task{}).Where("project_id = ?", id).
+			Updates(map[string]any{"project_id": nil, "milestone_id": nil}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.Milestone{}, "project_id = ?", id).Error
+	})
+}
+
+func (r *projectRepository) CreateMilestone(ctx context.Context, milestone *model.Milestone) error {
+	return r.db.WithContext(ctx).Create(milestone).Error
+}
+
+func (r *projectRepository) GetMilestone(ctx context.Context, projectID, id uuid.UUID) (*model.Milestone, error) {
+	var milestone model.Milestone
+	err := r.db.WithContext(ctx).First(&milestone, "project_id = ? AND id = ?", projectID, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &milestone, nil
+}
+
+// FindMilestone looks a milestone up by its ID alone
+func (r *projectRepository) FindMilestone(ctx context.Context, id uuid.UUID) (*model.Milestone, error) {
+	var milestone model.Milestone
+	err := r.db.WithContext(ctx).First(&milestone, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &milestone, nil
+}
+
+func (r *projectRepository) UpdateMilestone(ctx context.Context, milestone *model.Milestone) error {
+	return r.db.WithContext(ctx).Select("*").Save(milestone).Error
+}
+
+// DeleteMilestone deletes a milestone (soft delete), keeping its tasks in the
+// project, and reports whether it existed
+func (r *projectRepository) DeleteMilestone(ctx context.Context, projectID, id uuid.UUID) (bool, error) {
+	var removed bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.Milestone{}, "project_id = ? AND id = ?", projectID, id)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = true
+		return tx.Model(&model.Task{}).Where("milestone_id = ?", id).Update("milestone_id", nil).Error
+	})
+	return removed, err
+}
+
+// CountTasks counts the project's tasks by milestone and status
+func (r *projectRepository) CountTasks(ctx context.Context, projectID uuid.UUID) ([]TaskStatusCount, error) {
+	var counts []TaskStatusCount
+	err := r.scoped(ctx).Model(&model.Task{}).Select("milestone_id, status, COUNT(*) AS count").
+		Where("project_id = ?", projectID).
+		Group("milestone_id, status").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// scopedTx is scoped for a transaction
+func (r *projectRepository) scopedTx(tx *gorm.DB) *gorm.DB {
+	if r.workspaceID == nil {
+		return tx
+	}
+	return tx.Where("workspace_id = ?", *r.workspaceID)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *model.Role) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Role, error)
+	GetByName(ctx context.Context, name string) (*model.Role, error)
+	Update(ctx context.Context, role *model.Role) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]model.Role, error)
+	CountUsers(ctx context.Context, name string) (int64, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *model.Role) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Role, error) {
+	var role model.Role
+	err := r.db.WithContext(ctx).First(&role, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	err := r.db.WithContext(ctx).First(&role, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) Update(ctx context.Context, role *model.Role) error {
+	return r.db.WithContext(ctx).Save(role).Error
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Role{}, "id = ?", id).Error
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.WithContext(ctx).Order("name").Find(&roles).Error
+	return roles, err
+}
+
+// CountUsers returns how many users are assigned the named role
+func (r *roleRepository) CountUsers(ctx context.Context, name string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	Create(ctx context.Context, tag *model.Tag) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Tag, error)
+	GetByName(ctx context.Context, userID uuid.UUID, name string) (*model.Tag, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Tag, error)
+	Update(ctx context.Context, tag *model.Tag) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) error
+	AddToTask(ctx context.Context, taskID uuid.UUID, tagIDs []uuid.UUID) error
+	RemoveFromTask(ctx context.Context, taskID, tagID uuid.UUID) (bool, error)
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *model.Tag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.WithContext(ctx).First(&tag, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByName finds a tag of the user by name, ignoring case
+func (r *tagRepository) GetByName(ctx context.Context, userID uuid.UUID, name string) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.WithContext(ctx).First(&tag, "user_id = ? AND lower(name) = lower(?)", userID, name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByUserID returns the user's tags by name, each with the number of
+// tasks carrying it
+func (r *tagRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.db.WithContext(ctx).
+		Select(`tags.*, (
+			SELECT COUNT(*) FROM task_tags
+			JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL
+			WHERE task_tags.tag_id = tags.id
+		) AS usage_count`).
+		Where("user_id = ?", userID).
+		Order("lower(name)").
+		Find(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag *model.Tag) error {
+	return r.db.WithContext(ctx).Save(tag).Error
+}
+
+// Delete removes the tag; its task links go with it
+func (r *tagRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Tag{}, "id = ?", id).Error
+}
+
+// Merge moves every task link of the source tag to the target tag and
+// deletes the source tag
+func (r *tagRepository) Merge(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO task_tags (task_id, tag_id)
+			SELECT task_id, ? FROM task_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.Tag{}, "id = ?", sourceID).Error
+	})
+}
+
+// AddToTask links tags to a task, ignoring links that already exist
+func (r *tagRepository) AddToTask(ctx context.Context, taskID uuid.UUID, tagIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, tagID := range tagIDs {
+			err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", taskID, tagID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveFromTask unlinks a tag from a task and reports whether it was linked
+func (r *tagRepository) RemoveFromTask(ctx context.Context, taskID, tagID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Exec("DELETE FROM task_tags WHERE task_id = ? AND tag_id = ?", taskID, tagID)
+	return result.RowsAffected > 0, result.Error
+}
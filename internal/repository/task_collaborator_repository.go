@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskCollaboratorRepository stores who besides the owner works on a task:
+// its assignees and the users it is shared with
+type TaskCollaboratorRepository interface {
+	GetShares(ctx context.Context, taskID uuid.UUID) ([]model.TaskShare, error)
+	SaveShare(ctx context.Context, share *model.TaskShare) error
+	DeleteShare(ctx context.Context, taskID, userID uuid.UUID) (bool, error)
+	GetAssignees(ctx context.Context, taskID uuid.UUID) ([]model.User, error)
+	AddAssignee(ctx context.Context, taskID, userID uuid.UUID) error
+	RemoveAssignee(ctx context.Context, taskID, userID uuid.UUID) (bool, error)
+	CopyCollaborators(ctx context.Context, fromTaskID, toTaskID uuid.UUID) error
+}
+
+type taskCollaboratorRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskCollaboratorRepository(db *gorm.DB) TaskCollaboratorRepository {
+	return &taskCollaboratorRepository{db: db}
+}
+
+func (r *taskCollaboratorRepository) GetShares(ctx context.Context, taskID uuid.UUID) ([]model.TaskShare, error) {
+	var shares []model.TaskShare
+	err := r.db.WithContext(ctx).Preload("User").Where("task_id = ?", taskID).Order("created_at").Find(&shares).Error
+	return shares, err
+}
+
+// SaveShare creates the share or changes the permission of an existing one
+func (r *taskCollaboratorRepository) SaveShare(ctx context.Context, share *model.TaskShare) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"permission", "updated_at"}),
+	}).Create(share).Error
+}
+
+// DeleteShare removes a share and reports whether it existed
+func (r *taskCollaboratorRepository) DeleteShare(ctx context.Context, taskID, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&model.TaskShare{}, "task_id = ? AND user_id = ?", taskID, userID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetAssignees returns the users assigned to a task, in assignment order
+func (r *taskCollaboratorRepository) GetAssignees(ctx context.Context, taskID uuid.UUID) ([]model.User, error) {
+	var users []model.User
+	err := r.db.WithContext(ctx).
+		Joins("JOIN task_assignees ON task_assignees.user_id = users.id").
+		Where("task_assignees.task_id = ?", taskID).
+		Order("task_assignees.created_at").
+		Find(&users).Error
+	return users, err
+}
+
+// AddAssignee assigns a user to a task, ignoring an existing assignment
+func (r *taskCollaboratorRepository) AddAssignee(ctx context.Context, taskID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Exec("INSERT INTO task_assignees (task_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", taskID, userID).Error
+}
+
+// RemoveAssignee unassigns a user and reports whether they were assigned
+func (r *taskCollaboratorRepository) RemoveAssignee(ctx context.Context, taskID, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Exec("DELETE FROM task_assignees WHERE task_id = ? AND user_id = ?", taskID, userID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CopyCollaborators gives a task the assignees and shares of another task
+func (r *taskCollaboratorRepository) CopyCollaborators(ctx context.Context, fromTaskID, toTaskID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO task_assignees (task_id, user_id)
+			SELECT ?, user_id FROM task_assignees WHERE task_id = ?
+			ON CONFLICT DO NOTHING`, toTaskID, fromTaskID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO task_shares (task_id, user_id, permission, created_at, updated_at)
+			SELECT ?, user_id, permission, now(), now() FROM task_shares WHERE task_id = ?
+			ON CONFLICT DO NOTHING`, toTaskID, fromTaskID).Error
+	})
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaskDependencyRepository interface {
+	Create(ctx context.Context, dependency *model.TaskDependency) error
+	Exists(ctx context.Context, taskID, dependsOnID uuid.UUID) (bool, error)
+	Delete(ctx context.Context, taskID, dependsOnID uuid.UUID) (bool, error)
+	GetDependencies(ctx context.Context, taskID uuid.UUID) ([]model.Task, error)
+	GetUpstreamIDs(ctx context.Context, taskID uuid.UUID) ([]uuid.UUID, error)
+	GetBlockedIDs(ctx context.Context, taskIDs []uuid.UUID) ([]uuid.UUID, error)
+}
+
+type taskDependencyRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskDependencyRepository(db *gorm.DB) TaskDependencyRepository {
+	return &taskDependencyRepository{db: db}
+}
+
+func (r *taskDependencyRepository) Create(ctx context.Context, dependency *model.TaskDependency) error {
+	return r.db.WithContext(ctx).Create(dependency).Error
+}
+
+func (r *taskDependencyRepository) Exists(ctx context.Context, taskID, dependsOnID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.TaskDependency{}).
+		Where("task_id = ? AND depends_on_id = ?", taskID, dependsOnID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Delete removes a dependency and reports whether it existed
+func (r *taskDependencyRepository) Delete(ctx context.Context, taskID, dependsOnID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&model.TaskDependency{}, "task_id = ? AND depends_on_id = ?", taskID, dependsOnID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDependencies returns the tasks the given task is blocked by
+func (r *taskDependencyRepository) GetDependencies(ctx context.Context, taskID uuid.UUID) ([]model.Task, error) {
+	var tasks []model.Task
+	err := r.db.WithContext(ctx).Preload("Category").
+		Joins("JOIN task_dependencies ON task_dependencies.depends_on_id = tasks.id").
+		Where("task_dependencies.task_id = ?", taskID).
+		Order("task_dependencies.created_at").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// GetUpstreamIDs returns every task the given task depends on, directly or
+// through other dependencies
+func (r *taskDependencyRepository) GetUpstreamIDs(ctx context.Context, taskID uuid.UUID) ([]uuid.UUID, error) {
+	var rows []struct {
+		DependsOnID uuid.UUID
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE upstream AS (
+			SELECT depends_on_id FROM task_dependencies WHERE task_id = ?
+			UNION
+			SELECT d.depends_on_id FROM task_dependencies d
+			JOIN upstream u ON d.task_id = u.depends_on_id
+		)
+		SELECT depends_on_id FROM upstream`, taskID).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.DependsOnID
+	}
+	return ids, nil
+}
+
+// GetBlockedIDs returns which of the given tasks depend on a task that is
+// still open
+func (r *taskDependencyRepository) GetBlockedIDs(ctx context.Context, taskIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []struct {
+		TaskID uuid.UUID
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT d.task_id FROM task_dependencies d
+		JOIN tasks blocker ON blocker.id = d.depends_on_id
+		WHERE d.task_id IN ? AND blocker.deleted_at IS NULL AND blocker.status NOT IN ?`,
+		taskIDs, []model.TaskStatus{model.TaskStatusCompleted, model.TaskStatusCancelled}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.TaskID
+	}
+	return ids, nil
+}
@@ -2,75 +2,396 @@ package repository
 
 import (
 	"Arise-test/internal/model"
+	"context"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type TaskRepository interface {
-	Create(task *model.Task) error
-	GetByID(id uuid.UUID) (*model.Task, error)
-	GetByUserID(userID uuid.UUID, limit, offset int) ([]model.Task, error)
-	GetByStatus(userID uuid.UUID, status model.TaskStatus, limit, offset int) ([]model.Task, error)
-	GetByCategory(categoryID uuid.UUID, limit, offset int) ([]model.Task, error)
-	Update(task *model.Task) error
-	Delete(id uuid.UUID) error
-	List(limit, offset int) ([]model.Task, error)
+	Create(ctx context.Context, task *model.Task) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Task, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Task, error)
+	Find(ctx context.Context, filter model.TaskFilter, page model.PageRequest) (model.Page[model.Task], error)
+	GetByCategory(ctx context.Context, categoryID uuid.UUID, limit, offset int) ([]model.Task, error)
+	GetChildren(ctx context.Context, parentID uuid.UUID) ([]model.Task, error)
+	GetDescendants(ctx context.Context, id uuid.UUID) ([]model.Task, error)
+	GetAncestorIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	HasOccurrence(ctx context.Context, seriesID uuid.UUID, occurrence int) (bool, error)
+	Update(ctx context.Context, task *model.Task) error
+	// Delete soft deletes the task, only at the version when one is given
+	Delete(ctx context.Context, id uuid.UUID, version *int) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteByCategory(ctx context.Context, categoryID uuid.UUID) error
+	// MoveToCategory sets the category of the tasks in fromID, which may be
+	// nil to clear it. Tasks in the trash keep their category.
+	MoveToCategory(ctx context.Context, fromID uuid.UUID, toID *uuid.UUID) error
+	ApplyBulk(ctx context.Context, writes []BulkTaskWrite, atomic bool) ([]error, error)
+	// InWorkspace returns a repository whose queries only see the tasks of
+	// the workspace and which creates tasks in it
+	InWorkspace(workspaceID uuid.UUID) TaskRepository
+}
+
+// BulkTaskWrite is the change to one task of a bulk operation, validated by
+// the caller. Exactly one of Create, Update and Delete is set.
+type BulkTaskWrite struct {
+	Create *model.Task
+	Update *model.Task
+	Delete *uuid.UUID
+	// StatusChange records a status change made by Update
+	StatusChange *model.TaskStatusChange
+	// Successor is the next occurrence of a recurring task completed by
+	// Update, which gets the collaborators of the completed task
+	Successor *model.Task
 }
 
 type taskRepository struct {
-	db *gorm.DB
+	db          *gorm.DB
+	workspaceID *uuid.UUID
 }
 
+// NewTaskRepository creates a repository over the tasks of all workspaces.
+// Requests on behalf of a user go through InWorkspace.
 func NewTaskRepository(db *gorm.DB) TaskRepository {
 	return &taskRepository{db: db}
 }
 
-func (r *taskRepository) Create(task *model.Task) error {
-	return r.db.Create(task).Error
+func (r *taskRepository) InWorkspace(workspaceID uuid.UUID) TaskRepository {
+	return &taskRepository{db: r.db, workspaceID: &workspaceID}
+}
+
+// scoped starts a query limited to the repository's workspace
+func (r *taskRepository) scoped(ctx context.Context) *gorm.DB {
+	if r.workspaceID == nil {
+		return r.db.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx).Where("workspace_id = ?", *r.workspaceID)
+}
+
+// scopeSQL is the workspace condition of raw queries with its argument
+func (r *taskRepository) scopeSQL() (string, []any) {
+	if r.workspaceID == nil {
+		return "", nil
+	}
+	return " AND workspace_id = ?", []any{*r.workspaceID}
+}
+
+func (r *taskRepository) Create(ctx context.Context, task *model.Task) error {
+	if r.workspaceID != nil {
+		task.WorkspaceID = *r.workspaceID
+	}
+	return r.db.WithContext(ctx).Create(task).Error
 }
 
-func (r *taskRepository) GetByID(id uuid.UUID) (*model.Task, error) {
+func (r *taskRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Task, error) {
 	var task model.Task
-	err := r.db.Preload("User").Preload("Category").First(&task, "id = ?", id).Error
+	err := r.scoped(ctx).Preload("User").Preload("Category").Preload("Tags").
+		Preload("Assignees").Preload("Shares").First(&task, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (r *taskRepository) GetByUserID(userID uuid.UUID, limit, offset int) ([]model.Task, error) {
+func (r *taskRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Task, error) {
 	var tasks []model.Task
-	err := r.db.Preload("Category").Where("user_id = ?", userID).
+	err := r.scoped(ctx).Preload("Category").Where("user_id = ?", userID).
 		Limit(limit).Offset(offset).Find(&tasks).Error
 	return tasks, err
 }
 
-func (r *taskRepository) GetByStatus(userID uuid.UUID, status model.TaskStatus, limit, offset int) ([]model.Task, error) {
+// taskSortKeys maps sort fields to the keys tasks are ordered and paginated
+// by. Priority is ranked by urgency rather than alphabetically.
+var taskSortKeys = map[model.TaskSortField]sortKey{
+	model.TaskSortDueDate:   {name: "due_date", expr: "due_date", kind: kindTime, nullable: true},
+	model.TaskSortPriority:  {name: "priority", expr: "CASE priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END", kind: kindInt},
+	model.TaskSortCreatedAt: createdAtKey,
+	model.TaskSortUpdatedAt: {name: "updated_at", expr: "updated_at", kind: kindTime},
+	model.TaskSortTitle:     {name: "title", expr: "title", kind: kindString},
+}
+
+// priorityRanks mirrors the priority sort expression
+var priorityRanks = map[model.TaskPriority]int{
+	model.TaskPriorityUrgent: 4,
+	model.TaskPriorityHigh:   3,
+	model.TaskPriorityMedium: 2,
+	model.TaskPriorityLow:    1,
+}
+
+// likeEscaper escapes the LIKE wildcards in user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Find returns a page of the tasks matching every set field of the filter
+func (r *taskRepository) Find(ctx context.Context, filter model.TaskFilter, page model.PageRequest) (model.Page[model.Task], error) {
+	query := r.scoped(ctx).Model(&model.Task{})
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.AssigneeID != nil {
+		query = query.Where("id IN (SELECT task_id FROM task_assignees WHERE user_id = ?)", *filter.AssigneeID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("priority IN ?", filter.Priorities)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+	if filter.MilestoneID != nil {
+		query = query.Where("milestone_id = ?", *filter.MilestoneID)
+	}
+	if len(filter.TagIDs) > 0 {
+		if filter.AllTags {
+			query = query.Where(`id IN (
+				SELECT task_id FROM task_tags WHERE tag_id IN ?
+				GROUP BY task_id HAVING COUNT(DISTINCT tag_id) = ?)`, filter.TagIDs, len(uniqueIDs(filter.TagIDs)))
+		} else {
+			query = query.Where("id IN (SELECT task_id FROM task_tags WHERE tag_id IN ?)", filter.TagIDs)
+		}
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_date < ?", *filter.DueBefore)
+	}
+	if filter.DueAfter != nil {
+		query = query.Where("due_date > ?", *filter.DueAfter)
+	}
+	if filter.HasDueDate != nil {
+		if *filter.HasDueDate {
+			query = query.Where("due_date IS NOT NULL")
+		} else {
+			query = query.Where("due_date IS NULL")
+		}
+	}
+	if filter.Overdue {
+		query = query.Where("due_date < ? AND status NOT IN ?", time.Now(),
+			[]model.TaskStatus{model.TaskStatusCompleted, model.TaskStatusCancelled})
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		query = query.Where("updated_at > ?", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		query = query.Where("updated_at < ?", *filter.UpdatedBefore)
+	}
+	if filter.Query != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Query) + "%"
+		query = query.Where("(title ILIKE ? OR description ILIKE ?)", pattern, pattern)
+	}
+
+	// Reusable for both the count and the page query
+	query = query.Session(&gorm.Session{})
+
+	var result model.Page[model.Task]
+	total, err := countTotal(query, page)
+	if err != nil {
+		return result, err
+	}
+
+	key, ok := taskSortKeys[filter.SortBy]
+	if !ok {
+		key = createdAtKey
+	}
+	key.desc = filter.SortDesc
+
+	paged, err := paginate(query.Preload("Category").Preload("Tags"), key, page)
+	if err != nil {
+		return result, err
+	}
+
 	var tasks []model.Task
-	err := r.db.Preload("Category").Where("user_id = ? AND status = ?", userID, status).
+	if err := paged.Find(&tasks).Error; err != nil {
+		return result, err
+	}
+
+	result = buildPage(tasks, key, page, func(t *model.Task) (any, uuid.UUID) {
+		switch filter.SortBy {
+		case model.TaskSortDueDate:
+			return t.DueDate, t.ID
+		case model.TaskSortPriority:
+			return priorityRanks[t.Priority], t.ID
+		case model.TaskSortUpdatedAt:
+			return t.UpdatedAt, t.ID
+		case model.TaskSortTitle:
+			return t.Title, t.ID
+		default:
+			return t.CreatedAt, t.ID
+		}
+	})
+	result.Total = total
+	return result, nil
+}
+
+// uniqueIDs drops repeated IDs
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	var unique []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func (r *taskRepository) GetByCategory(ctx context.Context, categoryID uuid.UUID, limit, offset int) ([]model.Task, error) {
+	var tasks []model.Task
+	err := r.scoped(ctx).Preload("User").Where("category_id = ?", categoryID).
 		Limit(limit).Offset(offset).Find(&tasks).Error
 	return tasks, err
 }
 
-func (r *taskRepository) GetByCategory(categoryID uuid.UUID, limit, offset int) ([]model.Task, error) {
+func (r *taskRepository) GetChildren(ctx context.Context, parentID uuid.UUID) ([]model.Task, error) {
 	var tasks []model.Task
-	err := r.db.Preload("User").Where("category_id = ?", categoryID).
-		Limit(limit).Offset(offset).Find(&tasks).Error
+	err := r.scoped(ctx).Preload("Category").Where("parent_id = ?", parentID).
+		Order("created_at").Find(&tasks).Error
 	return tasks, err
 }
 
-func (r *taskRepository) Update(task *model.Task) error {
-	return r.db.Save(task).Error
+// GetDescendants returns every task below the given task, at any depth.
+// Subtasks always share their parent's workspace, so only the first level is
+// scoped.
+func (r *taskRepository) GetDescendants(ctx context.Context, id uuid.UUID) ([]model.Task, error) {
+	scope, args := r.scopeSQL()
+	var tasks []model.Task
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE descendants AS (
+			SELECT * FROM tasks WHERE parent_id = ? AND deleted_at IS NULL`+scope+`
+			UNION
+			SELECT t.* FROM tasks t
+			JOIN descendants d ON t.parent_id = d.id
+			WHERE t.deleted_at IS NULL
+		)
+		SELECT * FROM descendants ORDER BY created_at`, append([]any{id}, args...)...).Scan(&tasks).Error
+	return tasks, err
 }
 
-func (r *taskRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&model.Task{}, "id = ?", id).Error
+// GetAncestorIDs returns the IDs of every task above the given task
+func (r *taskRepository) GetAncestorIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	scope, args := r.scopeSQL()
+	var rows []struct {
+		ParentID uuid.UUID
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_id FROM tasks WHERE id = ? AND deleted_at IS NULL`+scope+`
+			UNION
+			SELECT t.parent_id FROM tasks t
+			JOIN ancestors a ON t.id = a.parent_id
+			WHERE t.deleted_at IS NULL
+		)
+		SELECT parent_id FROM ancestors WHERE parent_id IS NOT NULL`, append([]any{id}, args...)...).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ParentID
+	}
+	return ids, nil
 }
 
-func (r *taskRepository) List(limit, offset int) ([]model.Task, error) {
-	var tasks []model.Task
-	err := r.db.Preload("User").Preload("Category").
-		Limit(limit).Offset(offset).Find(&tasks).Error
-	return tasks, err
+// HasOccurrence reports whether the numbered occurrence of a recurring series
+// was ever generated, including occurrences that were deleted since
+func (r *taskRepository) HasOccurrence(ctx context.Context, seriesID uuid.UUID, occurrence int) (bool, error) {
+	var count int64
+	err := r.scoped(ctx).Unscoped().Model(&model.Task{}).
+		Where("series_id = ? AND occurrence = ?", seriesID, occurrence).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update saves the task and bumps its version, failing with
+// ErrVersionConflict when it changed or left the workspace since it was
+// loaded
+func (r *taskRepository) Update(ctx context.Context, task *model.Task) error {
+	return updateVersioned(r.scoped(ctx), task, &task.Version)
+}
+
+func (r *taskRepository) Delete(ctx context.Context, id uuid.UUID, version *int) error {
+	return deleteVersioned(r.scoped(ctx), &model.Task{}, id, version)
+}
+
+// DeleteByUserID deletes all of the user's tasks (soft delete)
+func (r *taskRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.scoped(ctx).Delete(&model.Task{}, "user_id = ?", userID).Error
+}
+
+// DeleteByCategory deletes the tasks of the category (soft delete)
+func (r *taskRepository) DeleteByCategory(ctx context.Context, categoryID uuid.UUID) error {
+	return r.scoped(ctx).Delete(&model.Task{}, "category_id = ?", categoryID).Error
+}
+
+func (r *taskRepository) MoveToCategory(ctx context.Context, fromID uuid.UUID, toID *uuid.UUID) error {
+	return r.scoped(ctx).Model(&model.Task{}).Where("category_id = ?", fromID).Update("category_id", toID).Error
+}
+
+// ApplyBulk performs the writes in one transaction. When atomic, the first
+// failing write rolls back all of them and its error is returned. Otherwise
+// every write runs in its own savepoint, so a failing write is skipped
+// without affecting the others. Either way the failures are reported at the
+// index of their write.
+func (r *taskRepository) ApplyBulk(ctx context.Context, writes []BulkTaskWrite, atomic bool) ([]error, error) {
+	failures := make([]error, len(writes))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, write := range writes {
+			apply := func(tx *gorm.DB) error {
+				return (&taskRepository{db: tx, workspaceID: r.workspaceID}).applyWrite(ctx, write)
+			}
+			if !atomic {
+				failures[i] = tx.Transaction(apply)
+				continue
+			}
+			if err := apply(tx); err != nil {
+				failures[i] = err
+				return err
+			}
+		}
+		return nil
+	})
+	return failures, err
+}
+
+// applyWrite performs one write of ApplyBulk within its transaction
+func (r *taskRepository) applyWrite(ctx context.Context, write BulkTaskWrite) error {
+	switch {
+	case write.Create != nil:
+		return r.Create(ctx, write.Create)
+	case write.Delete != nil:
+		result := r.scoped(ctx).Delete(&model.Task{}, "id = ?", *write.Delete)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	}
+
+	if err := r.Update(ctx, write.Update); err != nil {
+		return err
+	}
+	if write.StatusChange != nil {
+		if err := r.db.WithContext(ctx).Create(write.StatusChange).Error; err != nil {
+			return err
+		}
+	}
+	if write.Successor != nil {
+		if err := r.Create(ctx, write.Successor); err != nil {
+			return err
+		}
+		return NewTaskCollaboratorRepository(r.db).CopyCollaborators(ctx, write.Update.ID, write.Successor.ID)
+	}
+	return nil
 }
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaskStatusChangeRepository interface {
+	Create(ctx context.Context, change *model.TaskStatusChange) error
+	GetByTaskID(ctx context.Context, taskID uuid.UUID) ([]model.TaskStatusChange, error)
+}
+
+type taskStatusChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskStatusChangeRepository(db *gorm.DB) TaskStatusChangeRepository {
+	return &taskStatusChangeRepository{db: db}
+}
+
+func (r *taskStatusChangeRepository) Create(ctx context.Context, change *model.TaskStatusChange) error {
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+// GetByTaskID returns the status history of a task, oldest first
+func (r *taskStatusChangeRepository) GetByTaskID(ctx context.Context, taskID uuid.UUID) ([]model.TaskStatusChange, error) {
+	var changes []model.TaskStatusChange
+	err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at").Find(&changes).Error
+	return changes, err
+}
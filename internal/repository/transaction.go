@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repositories are the repositories of a unit of work, all bound to the same
+// transaction
+type Repositories struct {
+	Tasks         TaskRepository
+	Categories    CategoryRepository
+	Users         UserRepository
+	RefreshTokens RefreshTokenRepository
+	Boards        BoardRepository
+	StatusChanges TaskStatusChangeRepository
+	Collaborators TaskCollaboratorRepository
+	Invitations   InvitationRepository
+	Workspaces    WorkspaceRepository
+}
+
+type TxManager interface {
+	// WithinTransaction runs fn with repositories bound to one transaction.
+	// The transaction is committed when fn returns nil and rolled back when
+	// it returns an error or panics; the panic is then re-raised. Every call
+	// starts its own transaction, so work that must be part of the unit goes
+	// through the repositories passed to fn.
+	WithinTransaction(ctx context.Context, fn func(repos Repositories) error) error
+	// InWorkspace returns a manager whose task, category and board
+	// repositories are limited to the workspace
+	InWorkspace(workspaceID uuid.UUID) TxManager
+}
+
+type txManager struct {
+	db          *gorm.DB
+	workspaceID *uuid.UUID
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) InWorkspace(workspaceID uuid.UUID) TxManager {
+	return &txManager{db: m.db, workspaceID: &workspaceID}
+}
+
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(repos Repositories) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(m.repositories(tx))
+	})
+}
+
+// repositories builds the repositories of a unit of work over tx
+func (m *txManager) repositories(tx *gorm.DB) Repositories {
+	repos := Repositories{
+		Tasks:         NewTaskRepository(tx),
+		Categories:    NewCategoryRepository(tx),
+		Users:         NewUserRepository(tx),
+		RefreshTokens: NewRefreshTokenRepository(tx),
+		Boards:        NewBoardRepository(tx),
+		StatusChanges: NewTaskStatusChangeRepository(tx),
+		Collaborators: NewTaskCollaboratorRepository(tx),
+		Invitations:   NewInvitationRepository(tx),
+		Workspaces:    NewWorkspaceRepository(tx),
+	}
+	if m.workspaceID != nil {
+		repos.Tasks = repos.Tasks.InWorkspace(*m.workspaceID)
+		repos.Categories = repos.Categories.InWorkspace(*m.workspaceID)
+		repos.Boards = repos.Boards.InWorkspace(*m.workspaceID)
+	}
+	return repos
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TrashRepository reaches the soft-deleted tasks and categories that the
+// other repositories no longer see. Purging deletes rows for good and returns
+// the storage keys of the attachments that went with them, whose files the
+// caller still has to delete.
+type TrashRepository interface {
+	GetTasks(ctx context.Context, userID uuid.UUID) ([]model.Task, error)
+	GetCategories(ctx context.Context, userID uuid.UUID) ([]model.Category, error)
+	GetTask(ctx context.Context, id uuid.UUID) (*model.Task, error)
+	GetCategory(ctx context.Context, id uuid.UUID) (*model.Category, error)
+	RestoreTask(ctx context.Context, id uuid.UUID) error
+	RestoreCategory(ctx context.Context, id uuid.UUID) error
+	PurgeTask(ctx context.Context, id uuid.UUID) ([]string, error)
+	PurgeCategory(ctx context.Context, id uuid.UUID) error
+	PurgeUser(ctx context.Context, userID uuid.UUID) ([]string, error)
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, []string, error)
+	// InWorkspace returns a repository that only sees the trash of the
+	// workspace
+	InWorkspace(workspaceID uuid.UUID) TrashRepository
+}
+
+type trashRepository struct {
+	db          *gorm.DB
+	workspaceID *uuid.UUID
+}
+
+// NewTrashRepository creates a repository over the trash of all workspaces.
+// Requests on behalf of a user go through InWorkspace.
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func (r *trashRepository) InWorkspace(workspaceID uuid.UUID) TrashRepository {
+	return &trashRepository{db: r.db, workspaceID: &workspaceID}
+}
+
+// deleted starts a query over the soft-deleted rows of the repository's
+// workspace
+func (r *trashRepository) deleted(tx *gorm.DB) *gorm.DB {
+	query := tx.Unscoped().Where("deleted_at IS NOT NULL")
+	if r.workspaceID != nil {
+		query = query.Where("workspace_id = ?", *r.workspaceID)
+	}
+	return query
+}
+
+// GetTasks returns the user's deleted tasks, most recently deleted first
+func (r *trashRepository) GetTasks(ctx context.Context, userID uuid.UUID) ([]model.Task, error) {
+	var tasks []model.Task
+	err := r.deleted(r.db.WithContext(ctx)).Where("user_id = ?", userID).Order("deleted_at DESC").Find(&tasks).Error
+	return tasks, err
+}
+
+// GetCategories returns the user's deleted categories, most recently deleted
+// first
+func (r *trashRepository) GetCategories(ctx context.Context, userID uuid.UUID) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.deleted(r.db.WithContext(ctx)).Where("user_id = ?", userID).Order("deleted_at DESC").Find(&categories).Error
+	return categories, err
+}
+
+func (r *trashRepository) GetTask(ctx context.Context, id uuid.UUID) (*model.Task, error) {
+	var task model.Task
+	if err := r.deleted(r.db.WithContext(ctx)).First(&task, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *trashRepository) GetCategory(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	var category model.Category
+	if err := r.deleted(r.db.WithContext(ctx)).First(&category, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// RestoreTask brings a task back, together with its category when that was
+// deleted as well, so the task is linked to it again
+func (r *trashRepository) RestoreTask(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var task model.Task
+		if err := r.deleted(tx).First(&task, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := r.deleted(tx).Model(&task).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if task.CategoryID == nil {
+			return nil
+		}
+		return tx.Unscoped().Model(&model.Category{}).
+			Where("id = ? AND deleted_at IS NOT NULL", *task.CategoryID).
+			Update("deleted_at", nil).Error
+	})
+}
+
+func (r *trashRepository) RestoreCategory(ctx context.Context, id uuid.UUID) error {
+	result := r.deleted(r.db.WithContext(ctx)).Model(&model.Category{}).Where("id = ?", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeTask deletes a deleted task for good. Its comments, attachments and
+// other rows go with it; its subtasks are kept and unlinked, and its later
+// occurrences move to the next one as their series head.
+func (r *trashRepository) PurgeTask(ctx context.Context, id uuid.UUID) ([]string, error) {
+	var keys []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids, err := r.deletedTaskIDs(tx, "id = ?", id)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		keys, err = purgeTasks(tx, ids)
+		return err
+	})
+	return keys, err
+}
+
+// PurgeCategory deletes a deleted category for good; tasks still using it
+// lose their category
+func (r *trashRepository) PurgeCategory(ctx context.Context, id uuid.UUID) error {
+	result := r.deleted(r.db.WithContext(ctx)).Delete(&model.Category{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeUser empties the user's trash
+func (r *trashRepository) PurgeUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var keys []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids, err := r.deletedTaskIDs(tx, "user_id = ?", userID)
+		if err != nil {
+			return err
+		}
+		if keys, err = purgeTasks(tx, ids); err != nil {
+			return err
+		}
+		return r.deleted(tx).Delete(&model.Category{}, "user_id = ?", userID).Error
+	})
+	return keys, err
+}
+
+// PurgeDeletedBefore purges the tasks and categories of every workspace that
+// were deleted before the time, and reports how many rows were purged
+func (r *trashRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, []string, error) {
+	var purged int64
+	var keys []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids, err := r.deletedTaskIDs(tx, "deleted_at < ?", before)
+		if err != nil {
+			return err
+		}
+		if keys, err = purgeTasks(tx, ids); err != nil {
+			return err
+		}
+
+		result := r.deleted(tx).Delete(&model.Category{}, "deleted_at < ?", before)
+		if result.Error != nil {
+			return result.Error
+		}
+		purged = int64(len(ids)) + result.RowsAffected
+		return nil
+	})
+	return purged, keys, err
+}
+
+// deletedTaskIDs returns the IDs of the deleted tasks matching the condition
+func (r *trashRepository) deletedTaskIDs(tx *gorm.DB, query string, args ...any) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.deleted(tx.Model(&model.Task{})).Where(query, args...).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// purgeTasks deletes the tasks for good and returns the storage keys of
+// their attachments
+func purgeTasks(tx *gorm.DB, ids []uuid.UUID) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var keys []string
+	if err := tx.Model(&model.Attachment{}).Where("task_id IN ?", ids).Pluck("storage_key", &keys).Error; err != nil {
+		return nil, err
+	}
+	if err := repointSeries(tx, ids); err != nil {
+		return nil, err
+	}
+	return keys, tx.Unscoped().Delete(&model.Task{}, "id IN ?", ids).Error
+}
+
+// repointSeries makes the earliest surviving occurrence of each purged series
+// head the new head, so completing it keeps numbering the same series
+func repointSeries(tx *gorm.DB, ids []uuid.UUID) error {
+	var heads []uuid.UUID
+	err := tx.Unscoped().Model(&model.Task{}).Distinct("series_id").
+		Where("series_id IN ? AND id NOT IN ?", ids, ids).
+		Pluck("series_id", &heads).Error
+	if err != nil {
+		return err
+	}
+
+	for _, head := range heads {
+		var next model.Task
+		err := tx.Unscoped().Where("series_id = ? AND id NOT IN ?", head, ids).
+			Order("occurrence").First(&next).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&model.Task{}).Where("id = ?", next.ID).
+			UpdateColumn("series_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&model.Task{}).Where("series_id = ? AND id NOT IN ?", head, ids).
+			UpdateColumn("series_id", next.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
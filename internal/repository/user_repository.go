@@ -2,19 +2,23 @@ package repository
 
 import (
 	"Arise-test/internal/model"
+	"context"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
-	Create(user *model.User) error
-	GetByID(id uuid.UUID) (*model.User, error)
-	GetByEmail(email string) (*model.User, error)
-	GetByUsername(username string) (*model.User, error)
-	Update(user *model.User) error
-	Delete(id uuid.UUID) error
-	List(limit, offset int) ([]model.User, error)
+	Create(ctx context.Context, user *model.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	Update(ctx context.Context, user *model.User) error
+	// Delete soft deletes the user, only at the version when one is given
+	Delete(ctx context.Context, id uuid.UUID, version *int) error
+	CountOwned(ctx context.Context, id uuid.UUID) (int64, error)
+	List(ctx context.Context, page model.PageRequest) (model.Page[model.User], error)
 }
 
 type userRepository struct {
@@ -25,47 +29,97 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *model.User) error {
-	return r.db.Create(user).Error
+// Create adds the user together with their personal workspace, which has
+// the same ID as the user
+func (r *userRepository) Create(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		workspace := &model.Workspace{ID: user.ID, Name: user.Username, Personal: true}
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(&model.Membership{
+			WorkspaceID: workspace.ID,
+			UserID:      user.ID,
+			Role:        model.WorkspaceRoleOwner,
+		}).Error
+	})
 }
 
-func (r *userRepository) GetByID(id uuid.UUID) (*model.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, "id = ?", id).Error
+	err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetByEmail(email string) (*model.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, "email = ?", email).Error
+	err := r.db.WithContext(ctx).First(&user, "email = ?", email).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetByUsername(username string) (*model.User, error) {
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, "username = ?", username).Error
+	err := r.db.WithContext(ctx).First(&user, "username = ?", username).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) Update(user *model.User) error {
-	return r.db.Save(user).Error
+// Update saves the user and bumps their version, failing with
+// ErrVersionConflict when they changed since they were loaded
+func (r *userRepository) Update(ctx context.Context, user *model.User) error {
+	return updateVersioned(r.db.WithContext(ctx), user, &user.Version)
 }
 
-func (r *userRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&model.User{}, "id = ?", id).Error
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID, version *int) error {
+	return deleteVersioned(r.db.WithContext(ctx), &model.User{}, id, version)
 }
 
-func (r *userRepository) List(limit, offset int) ([]model.User, error) {
+// CountOwned counts the user's tasks and categories
+func (r *userRepository) CountOwned(ctx context.Context, id uuid.UUID) (int64, error) {
+	var tasks, categories int64
+	if err := r.db.WithContext(ctx).Model(&model.Task{}).Where("user_id = ?", id).Count(&tasks).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Category{}).Where("user_id = ?", id).Count(&categories).Error; err != nil {
+		return 0, err
+	}
+	return tasks + categories, nil
+}
+
+// List returns a page of all users, oldest first
+func (r *userRepository) List(ctx context.Context, page model.PageRequest) (model.Page[model.User], error) {
+	query := r.db.WithContext(ctx).Model(&model.User{}).Session(&gorm.Session{})
+
+	var result model.Page[model.User]
+	total, err := countTotal(query, page)
+	if err != nil {
+		return result, err
+	}
+
+	paged, err := paginate(query, createdAtKey, page)
+	if err != nil {
+		return result, err
+	}
+
 	var users []model.User
-	err := r.db.Limit(limit).Offset(offset).Find(&users).Error
-	return users, err
+	if err := paged.Find(&users).Error; err != nil {
+		return result, err
+	}
+
+	result = buildPage(users, createdAtKey, page, func(u *model.User) (any, uuid.UUID) {
+		return u.CreatedAt, u.ID
+	})
+	result.Total = total
+	return result, nil
 }
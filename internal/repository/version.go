@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrVersionConflict is returned when a row changed since it was loaded
+var ErrVersionConflict = errors.New("resource was modified by another request")
+
+// updateVersioned saves every column of a row loaded at *version and bumps
+// the version. The update only applies while the stored row still has the
+// loaded version, so concurrent writers cannot overwrite each other.
+func updateVersioned(db *gorm.DB, value any, version *int) error {
+	loaded := *version
+	*version = loaded + 1
+
+	// Preloaded associations are read-only views; only the row itself is
+	// saved
+	result := db.Model(value).Where("version = ?", loaded).
+		Select("*").Omit(clause.Associations).Updates(value)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = loaded
+	}
+	return result.Error
+}
+
+// deleteVersioned deletes the row with the ID. Given a version, the delete
+// only applies while the stored row still has it and fails with
+// ErrVersionConflict otherwise; nil deletes the row at any version.
+func deleteVersioned(db *gorm.DB, value any, id uuid.UUID, version *int) error {
+	if version == nil {
+		return db.Delete(value, "id = ?", id).Error
+	}
+	result := db.Where("version = ?", *version).Delete(value, "id = ?", id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	return result.Error
+}
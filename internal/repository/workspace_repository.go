@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"Arise-test/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkspaceRepository stores workspaces and their memberships
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace *model.Workspace, ownerID uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Workspace, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Workspace, error)
+	Update(ctx context.Context, workspace *model.Workspace) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetMembership(ctx context.Context, workspaceID, userID uuid.UUID) (*model.Membership, error)
+	GetMembers(ctx context.Context, workspaceID uuid.UUID) ([]model.Membership, error)
+	GetMemberByUsername(ctx context.Context, workspaceID uuid.UUID, username string) (*model.UserSummary, error)
+	SaveMembership(ctx context.Context, membership *model.Membership) error
+	DeleteMembership(ctx context.Context, workspaceID, userID uuid.UUID) (bool, error)
+	CountOwners(ctx context.Context, workspaceID uuid.UUID) (int64, error)
+	CountSoleOwned(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteMemberships(ctx context.Context, userID uuid.UUID) error
+}
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+// Create adds the workspace with the given user as its owner
+func (r *workspaceRepository) Create(ctx context.Context, workspace *model.Workspace, ownerID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(&model.Membership{
+			WorkspaceID: workspace.ID,
+			UserID:      ownerID,
+			Role:        model.WorkspaceRoleOwner,
+		}).Error
+	})
+}
+
+func (r *workspaceRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Workspace, error) {
+	var workspace model.Workspace
+	err := r.db.WithContext(ctx).First(&workspace, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// GetByUserID returns the workspaces the user is a member of, with their
+// role in each, personal workspace first
+func (r *workspaceRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Workspace, error) {
+	var workspaces []model.Workspace
+	err := r.db.WithContext(ctx).Select("workspaces.*, memberships.role AS role").
+		Joins("JOIN memberships ON memberships.workspace_id = workspaces.id").
+		Where("memberships.user_id = ?", userID).
+		Order("workspaces.personal DESC, workspaces.created_at").
+		Find(&workspaces).Error
+	return workspaces, err
+}
+
+func (r *workspaceRepository) Update(ctx context.Context, workspace *model.Workspace) error {
+	return r.db.WithContext(ctx).Model(workspace).Select("name", "updated_at").Updates(workspace).Error
+}
+
+// Delete soft-deletes the workspace together with its tasks and categories
+func (r *workspaceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_id = ?", id).Delete(&model.Task{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workspace_id = ?", id).Delete(&model.Category{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Workspace{}, "id = ?", id).Error
+	})
+}
+
+// GetMembership returns the user's membership of a workspace that has not
+// been deleted
+func (r *workspaceRepository) GetMembership(ctx context.Context, workspaceID, userID uuid.UUID) (*model.Membership, error) {
+	var membership model.Membership
+	err := r.db.WithContext(ctx).
+		Joins("JOIN workspaces ON workspaces.id = memberships.workspace_id AND workspaces.deleted_at IS NULL").
+		First(&membership, "memberships.workspace_id = ? AND memberships.user_id = ?", workspaceID, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// GetMembers returns the memberships of a workspace, in joining order
+func (r *workspaceRepository) GetMembers(ctx context.Context, workspaceID uuid.UUID) ([]model.Membership, error) {
+	var members []model.Membership
+	err := r.db.WithContext(ctx).Preload("User").Where("workspace_id = ?", workspaceID).
+		Order("created_at").Find(&members).Error
+	return members, err
+}
+
+// GetMemberByUsername returns the member of a workspace with the given
+// username
+func (r *workspaceRepository) GetMemberByUsername(ctx context.Context, workspaceID uuid.UUID, username string) (*model.UserSummary, error) {
+	var user model.UserSummary
+	err := r.db.WithContext(ctx).
+		Joins("JOIN memberships ON memberships.user_id = users.id AND memberships.workspace_id = ?", workspaceID).
+		First(&user, "users.username = ?", username).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SaveMembership adds the member or changes the role of an existing one
+func (r *workspaceRepository) SaveMembership(ctx context.Context, membership *model.Membership) error {
+	membership.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(membership).Error
+}
+
+// DeleteMembership removes a member and reports whether they were one
+func (r *workspaceRepository) DeleteMembership(ctx context.Context, workspaceID, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&model.Membership{}, "workspace_id = ? AND user_id = ?", workspaceID, userID)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *workspaceRepository) CountOwners(ctx context.Context, workspaceID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Membership{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, model.WorkspaceRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+// CountSoleOwned counts the team workspaces that have the user as their
+// only owner
+func (r *workspaceRepository) CountSoleOwned(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Membership{}).
+		Joins("JOIN workspaces ON workspaces.id = memberships.workspace_id AND workspaces.deleted_at IS NULL AND NOT workspaces.personal").
+		Where("memberships.user_id = ? AND memberships.role = ?", userID, model.WorkspaceRoleOwner).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM memberships owners
+			WHERE owners.workspace_id = memberships.workspace_id AND owners.role = ? AND owners.user_id <> memberships.user_id)`,
+			model.WorkspaceRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteMemberships removes the user from every workspace
+func (r *workspaceRepository) DeleteMemberships(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Membership{}, "user_id = ?", userID).Error
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"Arise-test/internal/handler"
+	"Arise-test/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,39 +12,188 @@ func SetupRoutes(
 	userHandler *handler.UserHandler,
 	taskHandler *handler.TaskHandler,
 	categoryHandler *handler.CategoryHandler,
+	authHandler *handler.AuthHandler,
+	roleHandler *handler.RoleHandler,
+	commentHandler *handler.CommentHandler,
+	attachmentHandler *handler.AttachmentHandler,
+	tagHandler *handler.TagHandler,
+	collaboratorHandler *handler.CollaboratorHandler,
+	workspaceHandler *handler.WorkspaceHandler,
+	projectHandler *handler.ProjectHandler,
+	boardHandler *handler.BoardHandler,
+	trashHandler *handler.TrashHandler,
+	authMiddleware gin.HandlerFunc,
+	workspaceMiddleware gin.HandlerFunc,
+	requireIfMatch gin.HandlerFunc,
+	idempotent gin.HandlerFunc,
+	queryTimeout gin.HandlerFunc,
+	requirePermission func(model.Permission) gin.HandlerFunc,
 ) {
-	// API v1 group
-	v1 := router.Group("/api/v1")
+	// API v1 group; every route but attachment uploads and downloads is
+	// bounded by the query timeout, so large files are not cut off
+	api := router.Group("/api/v1")
+	v1 := api.Group("", queryTimeout)
 	{
+		// Auth routes
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/logout", authHandler.Logout)
+		}
+
 		// User routes
 		users := v1.Group("/users")
 		{
-			users.POST("/", userHandler.CreateUser)
+			users.POST("/", idempotent, userHandler.CreateUser)
 			users.GET("/:id", userHandler.GetUser)
-			users.PUT("/:id", userHandler.UpdateUser)
-			users.DELETE("/:id", userHandler.DeleteUser)
-			users.GET("/", userHandler.ListUsers)
+			users.PUT("/:id", authMiddleware, requireIfMatch, userHandler.UpdateUser)
+			users.PATCH("/:id", authMiddleware, requireIfMatch, userHandler.PatchUser)
+			users.DELETE("/:id", authMiddleware, requireIfMatch, userHandler.DeleteUser)
+		}
+
+		// Workspace routes
+		workspaces := v1.Group("/workspaces", authMiddleware)
+		{
+			workspaces.POST("/", workspaceHandler.CreateWorkspace)
+			workspaces.GET("/:id", workspaceHandler.GetWorkspace)
+			workspaces.PUT("/:id", workspaceHandler.UpdateWorkspace)
+			workspaces.DELETE("/:id", workspaceHandler.DeleteWorkspace)
+			workspaces.GET("/:id/members", workspaceHandler.GetMembers)
+			workspaces.PUT("/:id/members/:userId", workspaceHandler.UpdateMemberRole)
+			workspaces.DELETE("/:id/members/:userId", workspaceHandler.RemoveMember)
+			workspaces.GET("/:id/invitations", workspaceHandler.GetInvitations)
+			workspaces.POST("/:id/invitations", workspaceHandler.CreateInvitation)
+			workspaces.DELETE("/:id/invitations/:invitationId", workspaceHandler.RevokeInvitation)
+			workspaces.GET("/", workspaceHandler.GetUserWorkspaces)
+		}
+
+		// Invitation routes; registering is for users without an account
+		invitations := v1.Group("/invitations")
+		{
+			invitations.POST("/accept", authMiddleware, workspaceHandler.AcceptInvitation)
+			invitations.POST("/register", workspaceHandler.RegisterWithInvitation)
 		}
 
-		// Task routes
-		tasks := v1.Group("/tasks")
+		// Task routes act in the workspace selected by the X-Workspace-ID header
+		tasks := v1.Group("/tasks", authMiddleware, workspaceMiddleware)
 		{
-			tasks.POST("/", taskHandler.CreateTask)
+			tasks.POST("/", idempotent, taskHandler.CreateTask)
+			tasks.POST("/bulk", idempotent, taskHandler.BulkTasks)
+			tasks.GET("/assigned", taskHandler.GetAssignedTasks)
 			tasks.GET("/:id", taskHandler.GetTask)
-			tasks.PUT("/:id", taskHandler.UpdateTask)
-			tasks.DELETE("/:id", taskHandler.DeleteTask)
+			tasks.PUT("/:id", requireIfMatch, taskHandler.UpdateTask)
+			tasks.PATCH("/:id", requireIfMatch, taskHandler.PatchTask)
+			tasks.DELETE("/:id", requireIfMatch, taskHandler.DeleteTask)
+			tasks.PUT("/:id/status", requireIfMatch, taskHandler.UpdateTaskStatus)
+			tasks.POST("/:id/move", requireIfMatch, taskHandler.MoveTask)
+			tasks.GET("/:id/history", taskHandler.GetStatusHistory)
+			tasks.GET("/:id/children", taskHandler.GetSubtasks)
+			tasks.GET("/:id/tree", taskHandler.GetTaskTree)
+			tasks.GET("/:id/dependencies", taskHandler.GetDependencies)
+			tasks.POST("/:id/dependencies", taskHandler.AddDependency)
+			tasks.DELETE("/:id/dependencies/:dependsOnId", taskHandler.RemoveDependency)
+			tasks.GET("/:id/comments", commentHandler.GetComments)
+			tasks.POST("/:id/comments", commentHandler.CreateComment)
+			tasks.PUT("/:id/comments/:commentId", commentHandler.UpdateComment)
+			tasks.DELETE("/:id/comments/:commentId", commentHandler.DeleteComment)
+			tasks.GET("/:id/comments/:commentId/history", commentHandler.GetCommentHistory)
+			tasks.GET("/:id/attachments", attachmentHandler.GetAttachments)
+			tasks.DELETE("/:id/attachments/:attachmentId", attachmentHandler.DeleteAttachment)
+			tasks.POST("/:id/tags", tagHandler.AddTaskTags)
+			tasks.DELETE("/:id/tags/:tagId", tagHandler.RemoveTaskTag)
+			tasks.GET("/:id/assignees", collaboratorHandler.GetAssignees)
+			tasks.POST("/:id/assignees", collaboratorHandler.AssignTask)
+			tasks.DELETE("/:id/assignees/:userId", collaboratorHandler.UnassignTask)
+			tasks.GET("/:id/shares", collaboratorHandler.GetShares)
+			tasks.PUT("/:id/shares/:userId", collaboratorHandler.ShareTask)
+			tasks.DELETE("/:id/shares/:userId", collaboratorHandler.UnshareTask)
 			tasks.GET("/", taskHandler.GetUserTasks)
 		}
 
+		// Attachment contents stream without the query timeout
+		attachments := api.Group("/tasks/:id/attachments", authMiddleware, workspaceMiddleware)
+		{
+			attachments.POST("", attachmentHandler.UploadAttachment)
+			attachments.GET("/:attachmentId", attachmentHandler.DownloadAttachment)
+		}
+
 		// Category routes
-		categories := v1.Group("/categories")
+		categories := v1.Group("/categories", authMiddleware, workspaceMiddleware)
 		{
-			categories.POST("/", categoryHandler.CreateCategory)
+			categories.POST("/", idempotent, categoryHandler.CreateCategory)
 			categories.GET("/:id", categoryHandler.GetCategory)
-			categories.PUT("/:id", categoryHandler.UpdateCategory)
-			categories.DELETE("/:id", categoryHandler.DeleteCategory)
+			categories.PUT("/:id", requireIfMatch, categoryHandler.UpdateCategory)
+			categories.PATCH("/:id", requireIfMatch, categoryHandler.PatchCategory)
+			categories.DELETE("/:id", requireIfMatch, categoryHandler.DeleteCategory)
 			categories.GET("/", categoryHandler.GetUserCategories)
 		}
+
+		// Project routes
+		projects := v1.Group("/projects", authMiddleware, workspaceMiddleware)
+		{
+			projects.POST("/", projectHandler.CreateProject)
+			projects.GET("/:id", projectHandler.GetProject)
+			projects.PUT("/:id", projectHandler.UpdateProject)
+			projects.DELETE("/:id", projectHandler.DeleteProject)
+			projects.GET("/:id/progress", projectHandler.GetProjectProgress)
+			projects.POST("/:id/milestones", projectHandler.CreateMilestone)
+			projects.PUT("/:id/milestones/:milestoneId", projectHandler.UpdateMilestone)
+			projects.DELETE("/:id/milestones/:milestoneId", projectHandler.DeleteMilestone)
+			projects.GET("/:id/milestones/:milestoneId/progress", projectHandler.GetMilestoneProgress)
+			projects.GET("/", projectHandler.GetUserProjects)
+		}
+
+		// Board routes
+		boards := v1.Group("/boards", authMiddleware, workspaceMiddleware)
+		{
+			boards.POST("/", boardHandler.CreateBoard)
+			boards.GET("/:id", boardHandler.GetBoard)
+			boards.PUT("/:id", boardHandler.UpdateBoard)
+			boards.DELETE("/:id", boardHandler.DeleteBoard)
+			boards.POST("/:id/columns", boardHandler.CreateColumn)
+			boards.PUT("/:id/columns/:columnId", boardHandler.UpdateColumn)
+			boards.DELETE("/:id/columns/:columnId", boardHandler.DeleteColumn)
+			boards.GET("/", boardHandler.GetUserBoards)
+		}
+
+		// Trash routes
+		trash := v1.Group("/trash", authMiddleware, workspaceMiddleware)
+		{
+			trash.GET("/", trashHandler.GetTrash)
+			trash.DELETE("/", trashHandler.EmptyTrash)
+			trash.POST("/tasks/:id/restore", trashHandler.RestoreTask)
+			trash.DELETE("/tasks/:id", trashHandler.PurgeTask)
+			trash.POST("/categories/:id/restore", trashHandler.RestoreCategory)
+			trash.DELETE("/categories/:id", trashHandler.PurgeCategory)
+		}
+
+		// Tag routes
+		tags := v1.Group("/tags", authMiddleware, workspaceMiddleware)
+		{
+			tags.POST("/", tagHandler.CreateTag)
+			tags.PUT("/:id", tagHandler.UpdateTag)
+			tags.DELETE("/:id", tagHandler.DeleteTag)
+			tags.POST("/:id/merge", tagHandler.MergeTag)
+			tags.GET("/", tagHandler.GetUserTags)
+		}
+
+		// Admin routes
+		admin := v1.Group("/admin", authMiddleware)
+		{
+			admin.GET("/users", requirePermission(model.PermissionUsersList), userHandler.ListUsers)
+			admin.PUT("/users/:id/role", requirePermission(model.PermissionUsersManage), roleHandler.AssignRole)
+			admin.GET("/tasks", requirePermission(model.PermissionTasksListAll), taskHandler.ListTasks)
+			admin.GET("/categories", requirePermission(model.PermissionCategoriesListAll), categoryHandler.ListCategories)
+
+			roles := admin.Group("/roles", requirePermission(model.PermissionRolesManage))
+			{
+				roles.POST("/", roleHandler.CreateRole)
+				roles.GET("/", roleHandler.ListRoles)
+				roles.PUT("/:id", roleHandler.UpdateRole)
+				roles.DELETE("/:id", roleHandler.DeleteRole)
+			}
+		}
 	}
 
 	// Health check
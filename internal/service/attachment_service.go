@@ -0,0 +1,252 @@
+package service
+
+import (
+	"Arise-test/configs"
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"Arise-test/internal/storage"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentNotFound       = errors.New("attachment not found")
+	ErrAttachmentTooLarge       = errors.New("attachment is too large")
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type is not allowed")
+	ErrInvalidAttachment        = errors.New("invalid attachment")
+)
+
+// maxFileNameLength bounds the stored name of an attachment, in characters
+const maxFileNameLength = 255
+
+// AttachmentUpload is a file sent to be attached to a task
+type AttachmentUpload struct {
+	FileName string
+	Size     int64
+	// ContentType is the type declared by the client, used only when the
+	// contents do not reveal a more specific one
+	ContentType string
+	Content     io.Reader
+}
+
+type AttachmentService interface {
+	UploadAttachment(ctx context.Context, userID, taskID uuid.UUID, upload AttachmentUpload) (*model.Attachment, error)
+	GetAttachments(ctx context.Context, userID, taskID uuid.UUID) ([]model.Attachment, error)
+	// OpenAttachment returns the metadata and contents of an attachment; the
+	// caller closes the contents
+	OpenAttachment(ctx context.Context, userID, taskID, id uuid.UUID) (*model.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, userID, taskID, id uuid.UUID) error
+	// InWorkspace returns the service limited to the tasks of the
+	// membership's workspace
+	InWorkspace(membership model.Membership) AttachmentService
+}
+
+type attachmentService struct {
+	attachmentRepo repository.AttachmentRepository
+	taskRepo       repository.TaskRepository
+	storage        storage.Storage
+	config         configs.AttachmentConfig
+	membership     *model.Membership
+}
+
+func NewAttachmentService(attachmentRepo repository.AttachmentRepository, taskRepo repository.TaskRepository, storage storage.Storage, config configs.AttachmentConfig) AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		taskRepo:       taskRepo,
+		storage:        storage,
+		config:         config,
+	}
+}
+
+func (s *attachmentService) InWorkspace(membership model.Membership) AttachmentService {
+	scoped := *s
+	scoped.taskRepo = s.taskRepo.InWorkspace(membership.WorkspaceID)
+	scoped.membership = &membership
+	return &scoped
+}
+
+// UploadAttachment stores a file and records its metadata on one of the
+// user's tasks. The contents are hashed while they stream to storage.
+func (s *attachmentService) UploadAttachment(ctx context.Context, userID, taskID uuid.UUID, upload AttachmentUpload) (*model.Attachment, error) {
+	if err := s.checkTask(ctx, userID, taskID, model.TaskPermissionEdit); err != nil {
+		return nil, err
+	}
+
+	fileName, err := cleanFileName(upload.FileName)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Size <= 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidAttachment)
+	}
+	if s.config.MaxSize > 0 && upload.Size > s.config.MaxSize {
+		return nil, fmt.Errorf("%w: limit is %d bytes", ErrAttachmentTooLarge, s.config.MaxSize)
+	}
+
+	content := bufio.NewReader(io.LimitReader(upload.Content, upload.Size))
+	head, err := content.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	contentType := detectContentType(head, upload.ContentType)
+	if !s.typeAllowed(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrAttachmentTypeNotAllowed, contentType)
+	}
+
+	attachment := &model.Attachment{
+		ID:          uuid.New(),
+		TaskID:      taskID,
+		UserID:      userID,
+		FileName:    fileName,
+		Size:        upload.Size,
+		ContentType: contentType,
+	}
+	attachment.StorageKey = "tasks/" + taskID.String() + "/" + attachment.ID.String()
+
+	hash := sha256.New()
+	if err := s.storage.Put(ctx, attachment.StorageKey, io.TeeReader(content, hash), upload.Size, contentType); err != nil {
+		return nil, err
+	}
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		// Do not leave an object behind that no attachment refers to
+		s.deleteContents(ctx, attachment.StorageKey)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *attachmentService) GetAttachments(ctx context.Context, userID, taskID uuid.UUID) ([]model.Attachment, error) {
+	if err := s.checkTask(ctx, userID, taskID, model.TaskPermissionView); err != nil {
+		return nil, err
+	}
+
+	return s.attachmentRepo.ListByTask(ctx, taskID)
+}
+
+func (s *attachmentService) OpenAttachment(ctx context.Context, userID, taskID, id uuid.UUID) (*model.Attachment, io.ReadCloser, error) {
+	attachment, err := s.getAttachment(ctx, userID, taskID, id, model.TaskPermissionView)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment removes the metadata first, so a failure to delete the
+// contents leaves at worst an unreferenced object rather than a broken
+// attachment. The attachment is gone once its metadata is, so that failure
+// is only logged.
+func (s *attachmentService) DeleteAttachment(ctx context.Context, userID, taskID, id uuid.UUID) error {
+	attachment, err := s.getAttachment(ctx, userID, taskID, id, model.TaskPermissionEdit)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.deleteContents(ctx, attachment.StorageKey)
+	return nil
+}
+
+// deleteContents removes an object no attachment refers to any more, even
+// when the request was cancelled. A failure leaves the object orphaned and is
+// logged with its key.
+func (s *attachmentService) deleteContents(ctx context.Context, key string) {
+	if err := s.storage.Delete(context.WithoutCancel(ctx), key); err != nil {
+		log.Printf("Failed to delete attachment contents %s: %v", key, err)
+	}
+}
+
+// checkTask ensures the task exists and the user holds the permission on it
+func (s *attachmentService) checkTask(ctx context.Context, userID, taskID uuid.UUID, permission model.TaskPermission) error {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return notFoundAs(err, ErrTaskNotFound)
+	}
+	return authorizeTaskAccess(s.membership, userID, task, permission)
+}
+
+// getAttachment loads an attachment of the given task, on which the user
+// must hold the permission
+func (s *attachmentService) getAttachment(ctx context.Context, userID, taskID, id uuid.UUID, permission model.TaskPermission) (*model.Attachment, error) {
+	if err := s.checkTask(ctx, userID, taskID, permission); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrAttachmentNotFound)
+	}
+	if attachment.TaskID != taskID {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// typeAllowed matches a content type against the configured list, where
+// "type/*" accepts any subtype
+func (s *attachmentService) typeAllowed(contentType string) bool {
+	if len(s.config.AllowedTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, allowed := range s.config.AllowedTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// detectContentType sniffs the type from the first bytes of a file. The
+// declared type is used only when sniffing gives a generic answer, so a
+// client cannot pass off a file as a type its contents contradict.
+func detectContentType(head []byte, declared string) string {
+	detected := http.DetectContentType(head)
+
+	generic := detected == "application/octet-stream" || strings.HasPrefix(detected, "text/plain")
+	if generic && declared != "" {
+		if mediaType, params, err := mime.ParseMediaType(declared); err == nil {
+			return mime.FormatMediaType(mediaType, params)
+		}
+	}
+	return detected
+}
+
+// cleanFileName keeps the base name of an uploaded file, dropping any
+// directories a client may have sent
+func cleanFileName(name string) (string, error) {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "", fmt.Errorf("%w: file name is required", ErrInvalidAttachment)
+	}
+	if len([]rune(name)) > maxFileNameLength {
+		return "", fmt.Errorf("%w: file name is longer than %d characters", ErrInvalidAttachment, maxFileNameLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"Arise-test/configs"
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"Arise-test/pkg"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+)
+
+// TokenPair is returned on login and refresh
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type AuthService interface {
+	Login(ctx context.Context, identifier, password string) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string, allSessions bool) error
+	ValidateAccessToken(ctx context.Context, accessToken string) (uuid.UUID, error)
+}
+
+type authService struct {
+	userService      UserService
+	refreshTokenRepo repository.RefreshTokenRepository
+	config           configs.SecurityConfig
+}
+
+func NewAuthService(userService UserService, refreshTokenRepo repository.RefreshTokenRepository, config configs.SecurityConfig) AuthService {
+	return &authService{
+		userService:      userService,
+		refreshTokenRepo: refreshTokenRepo,
+		config:           config,
+	}
+}
+
+// Login checks the credentials and issues a new token pair. The identifier
+// may be either the username or the email address.
+func (s *authService) Login(ctx context.Context, identifier, password string) (*TokenPair, error) {
+	var user *model.User
+	var err error
+	if pkg.IsValidEmail(identifier) {
+		user, err = s.userService.GetUserByEmail(ctx, identifier)
+	} else {
+		user, err = s.userService.GetUserByUsername(ctx, identifier)
+	}
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !s.userService.ValidatePassword(user.Password, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issueTokens(ctx, user.ID, uuid.New())
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is revoked; presenting an already revoked token revokes its whole family.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	stored, err := s.refreshTokenRepo.GetByTokenHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if stored.RevokedAt != nil {
+		if err := s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.refreshTokenRepo.Revoke(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, ErrInvalidToken
+	}
+
+	if _, err := s.userService.GetUserByID(ctx, stored.UserID); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return s.issueTokens(ctx, stored.UserID, stored.FamilyID)
+}
+
+func (s *authService) Logout(ctx context.Context, refreshToken string, allSessions bool) error {
+	stored, err := s.refreshTokenRepo.GetByTokenHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if allSessions {
+		return s.refreshTokenRepo.RevokeByUserID(ctx, stored.UserID)
+	}
+	return s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID)
+}
+
+// ValidateAccessToken verifies the signature and expiry of an access token
+// and returns the user ID it was issued for. Tokens of users that have since
+// been deleted are rejected.
+func (s *authService) ValidateAccessToken(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(accessToken, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(s.config.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	if _, err := s.userService.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrInvalidToken
+		}
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+func (s *authService) issueTokens(ctx context.Context, userID, familyID uuid.UUID) (*TokenPair, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.config.AccessTokenTTL)
+
+	claims := jwt.RegisteredClaims{
+		Subject:   userID.String(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		ID:        uuid.NewString(),
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JWTSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.config.RefreshTokenTTL),
+	}
+	if err := s.refreshTokenRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// generateToken returns a random URL-safe token, used for refresh tokens and
+// invitations
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
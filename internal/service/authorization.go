@@ -0,0 +1,156 @@
+package service
+
+import (
+	"Arise-test/internal/model"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Resources a caller may not access are reported with the same errors as
+// resources that do not exist, so their existence is not leaked.
+var (
+	ErrTaskNotFound     = errors.New("task not found")
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrBoardNotFound    = errors.New("board not found")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+// ErrTaskForbidden is returned when a collaborator can see a task but their
+// permission does not allow the change
+var ErrTaskForbidden = errors.New("insufficient permission on task")
+
+// authorizeTask checks that the task belongs to the user
+func authorizeTask(userID uuid.UUID, task *model.Task) error {
+	if task.UserID != userID {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// taskPermission returns the user's permission on a task: owner for its
+// user, edit for its assignees, otherwise the level it was shared at. A
+// workspace role that gives more access than that wins. The task must have
+// its assignees and shares loaded.
+func taskPermission(membership *model.Membership, userID uuid.UUID, task *model.Task) (model.TaskPermission, bool) {
+	permission, ok := ownTaskPermission(userID, task)
+	if role, member := workspacePermission(membership, userID, task.WorkspaceID); member && (!ok || !permission.Includes(role)) {
+		return role, true
+	}
+	return permission, ok
+}
+
+func ownTaskPermission(userID uuid.UUID, task *model.Task) (model.TaskPermission, bool) {
+	if task.UserID == userID {
+		return model.TaskPermissionOwner, true
+	}
+	for _, assignee := range task.Assignees {
+		if assignee.ID == userID {
+			return model.TaskPermissionEdit, true
+		}
+	}
+	for _, share := range task.Shares {
+		if share.UserID == userID {
+			return share.Permission, true
+		}
+	}
+	return "", false
+}
+
+// workspacePermission returns the permission the user's workspace role gives
+// on every task and category of the workspace: owners and admins manage them
+// as if they were their own and members can view them. Guests, and services
+// that are not limited to a workspace, get none.
+func workspacePermission(membership *model.Membership, userID, workspaceID uuid.UUID) (model.TaskPermission, bool) {
+	if membership == nil || membership.UserID != userID || membership.WorkspaceID != workspaceID {
+		return "", false
+	}
+	if membership.Role.CanManage() {
+		return model.TaskPermissionOwner, true
+	}
+	if membership.Role == model.WorkspaceRoleMember {
+		return model.TaskPermissionView, true
+	}
+	return "", false
+}
+
+// authorizeTaskAccess checks that the user holds at least the required
+// permission on the task. Users without any access get ErrTaskNotFound.
+func authorizeTaskAccess(membership *model.Membership, userID uuid.UUID, task *model.Task, required model.TaskPermission) error {
+	permission, ok := taskPermission(membership, userID, task)
+	if !ok {
+		return ErrTaskNotFound
+	}
+	if !permission.Includes(required) {
+		return ErrTaskForbidden
+	}
+	return nil
+}
+
+// authorizeContribution checks that the workspace role allows creating and
+// changing tasks and categories. Services that are not limited to a
+// workspace have no membership and allow it.
+func authorizeContribution(membership *model.Membership) error {
+	if membership != nil && !membership.Role.CanContribute() {
+		return ErrWorkspaceForbidden
+	}
+	return nil
+}
+
+// authorizeCategory checks that the category belongs to the user
+func authorizeCategory(userID uuid.UUID, category *model.Category) error {
+	if category.UserID != userID {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// authorizeCategoryAccess checks that the category belongs to the user or
+// that their workspace role gives at least the required permission on it
+func authorizeCategoryAccess(membership *model.Membership, userID uuid.UUID, category *model.Category, required model.TaskPermission) error {
+	if category.UserID == userID {
+		return nil
+	}
+	permission, ok := workspacePermission(membership, userID, category.WorkspaceID)
+	if !ok {
+		return ErrCategoryNotFound
+	}
+	if !permission.Includes(required) {
+		return ErrWorkspaceForbidden
+	}
+	return nil
+}
+
+// authorizeProject checks that the project belongs to the user
+func authorizeProject(userID uuid.UUID, project *model.Project) error {
+	if project.UserID != userID {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// authorizeBoard checks that the board belongs to the user
+func authorizeBoard(userID uuid.UUID, board *model.Board) error {
+	if board.UserID != userID {
+		return ErrBoardNotFound
+	}
+	return nil
+}
+
+// authorizeUser checks that the actor is acting on their own account
+func authorizeUser(actorID, userID uuid.UUID) error {
+	if actorID != userID {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// notFoundAs replaces a missing-record error with the given not found error
+func notFoundAs(err, notFoundErr error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFoundErr
+	}
+	return err
+}
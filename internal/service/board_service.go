@@ -0,0 +1,234 @@
+package service
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrColumnNotFound = errors.New("column not found")
+	ErrInvalidBoard   = errors.New("invalid board")
+)
+
+type BoardService interface {
+	CreateBoard(ctx context.Context, board *model.Board) error
+	GetBoardByID(ctx context.Context, userID, id uuid.UUID) (*model.Board, error)
+	GetBoardsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Board, error)
+	UpdateBoard(ctx context.Context, userID uuid.UUID, board *model.Board) error
+	DeleteBoard(ctx context.Context, userID, id uuid.UUID) error
+	CreateColumn(ctx context.Context, userID uuid.UUID, column *model.BoardColumn) error
+	GetColumn(ctx context.Context, userID, boardID, id uuid.UUID) (*model.BoardColumn, error)
+	UpdateColumn(ctx context.Context, userID uuid.UUID, column *model.BoardColumn) error
+	DeleteColumn(ctx context.Context, userID, boardID, id uuid.UUID) error
+	// InWorkspace returns the service limited to the boards of the
+	// membership's workspace, which guests can only read
+	InWorkspace(membership model.Membership) BoardService
+}
+
+type boardService struct {
+	boardRepo  repository.BoardRepository
+	membership *model.Membership
+}
+
+func NewBoardService(boardRepo repository.BoardRepository) BoardService {
+	return &boardService{
+		boardRepo: boardRepo,
+	}
+}
+
+func (s *boardService) InWorkspace(membership model.Membership) BoardService {
+	return &boardService{
+		boardRepo:  s.boardRepo.InWorkspace(membership.WorkspaceID),
+		membership: &membership,
+	}
+}
+
+// CreateBoard creates a board with the columns it is given, in that order
+func (s *boardService) CreateBoard(ctx context.Context, board *model.Board) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if board.UserID == uuid.Nil {
+		return errors.New("user ID is required")
+	}
+
+	if err := validateBoard(board); err != nil {
+		return err
+	}
+	for i := range board.Columns {
+		if err := validateColumn(&board.Columns[i]); err != nil {
+			return err
+		}
+	}
+
+	return s.boardRepo.Create(ctx, board)
+}
+
+// GetBoardByID returns one of the user's boards with its columns and their
+// tasks in order
+func (s *boardService) GetBoardByID(ctx context.Context, userID, id uuid.UUID) (*model.Board, error) {
+	board, err := s.boardRepo.GetWithTasks(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrBoardNotFound)
+	}
+
+	if err := authorizeBoard(userID, board); err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+func (s *boardService) GetBoardsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Board, error) {
+	return s.boardRepo.GetByUserID(ctx, userID)
+}
+
+func (s *boardService) UpdateBoard(ctx context.Context, userID uuid.UUID, board *model.Board) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	existing, err := s.getBoard(ctx, userID, board.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateBoard(board); err != nil {
+		return err
+	}
+
+	// Ownership and workspace cannot be changed through an update
+	board.UserID = existing.UserID
+	board.WorkspaceID = existing.WorkspaceID
+	board.UpdatedAt = time.Now()
+
+	return s.boardRepo.Update(ctx, board)
+}
+
+// DeleteBoard deletes a board and its columns. Its tasks are kept but no
+// longer on a board.
+func (s *boardService) DeleteBoard(ctx context.Context, userID, id uuid.UUID) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if _, err := s.getBoard(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.boardRepo.Delete(ctx, id)
+}
+
+// CreateColumn adds a column at the end of a board
+func (s *boardService) CreateColumn(ctx context.Context, userID uuid.UUID, column *model.BoardColumn) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if _, err := s.getBoard(ctx, userID, column.BoardID); err != nil {
+		return err
+	}
+
+	if err := validateColumn(column); err != nil {
+		return err
+	}
+
+	return s.boardRepo.CreateColumn(ctx, column)
+}
+
+// GetColumn returns a column of one of the user's boards
+func (s *boardService) GetColumn(ctx context.Context, userID, boardID, id uuid.UUID) (*model.BoardColumn, error) {
+	if _, err := s.getBoard(ctx, userID, boardID); err != nil {
+		return nil, err
+	}
+
+	column, err := s.boardRepo.GetColumn(ctx, boardID, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrColumnNotFound)
+	}
+	return column, nil
+}
+
+// UpdateColumn renames a column or changes its status. Tasks already in the
+// column keep their status until they are moved.
+func (s *boardService) UpdateColumn(ctx context.Context, userID uuid.UUID, column *model.BoardColumn) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	existing, err := s.GetColumn(ctx, userID, column.BoardID, column.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateColumn(column); err != nil {
+		return err
+	}
+
+	// The position only changes through the rank
+	column.Rank = existing.Rank
+	column.CreatedAt = existing.CreatedAt
+	column.UpdatedAt = time.Now()
+	return s.boardRepo.UpdateColumn(ctx, column)
+}
+
+// DeleteColumn deletes a column, taking its tasks off the board
+func (s *boardService) DeleteColumn(ctx context.Context, userID, boardID, id uuid.UUID) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if _, err := s.getBoard(ctx, userID, boardID); err != nil {
+		return err
+	}
+
+	removed, err := s.boardRepo.DeleteColumn(ctx, boardID, id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrColumnNotFound
+	}
+	return nil
+}
+
+// getBoard loads one of the user's boards with its columns but not its tasks
+func (s *boardService) getBoard(ctx context.Context, userID, id uuid.UUID) (*model.Board, error) {
+	board, err := s.boardRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrBoardNotFound)
+	}
+
+	if err := authorizeBoard(userID, board); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+func validateBoard(board *model.Board) error {
+	board.Name = strings.TrimSpace(board.Name)
+	if board.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidBoard)
+	}
+	return nil
+}
+
+// validateColumn checks the name of a column and the status it maps to
+func validateColumn(column *model.BoardColumn) error {
+	column.Name = strings.TrimSpace(column.Name)
+	if column.Name == "" {
+		return fmt.Errorf("%w: column name is required", ErrInvalidBoard)
+	}
+	if column.Status != nil && !column.Status.IsValid() {
+		return fmt.Errorf("%w %q", ErrInvalidStatus, *column.Status)
+	}
+	return nil
+}
@@ -3,58 +3,159 @@ package service
 import (
 	"Arise-test/internal/model"
 	"Arise-test/internal/repository"
+	"context"
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrInvalidCategory     = errors.New("invalid category")
+	ErrInvalidDeletePolicy = errors.New("invalid delete policy")
+)
+
 type CategoryService interface {
-	CreateCategory(category *model.Category) error
-	GetCategoryByID(id uuid.UUID) (*model.Category, error)
-	GetCategoriesByUserID(userID uuid.UUID) ([]model.Category, error)
-	UpdateCategory(category *model.Category) error
-	DeleteCategory(id uuid.UUID) error
-	ListCategories(limit, offset int) ([]model.Category, error)
+	CreateCategory(ctx context.Context, category *model.Category) error
+	GetCategoryByID(ctx context.Context, userID, id uuid.UUID) (*model.Category, error)
+	GetCategoriesByUserID(ctx context.Context, userID uuid.UUID) ([]model.Category, error)
+	UpdateCategory(ctx context.Context, userID uuid.UUID, category *model.Category) error
+	DeleteCategory(ctx context.Context, userID, id uuid.UUID, version *int, policy model.CategoryDeletePolicy, targetID *uuid.UUID) error
+	ListCategories(ctx context.Context, page model.PageRequest) (model.Page[model.Category], error)
+	// InWorkspace returns the service limited to the categories of the
+	// membership's workspace, which guests can only read
+	InWorkspace(membership model.Membership) CategoryService
 }
 
 type categoryService struct {
 	categoryRepo repository.CategoryRepository
+	txManager    repository.TxManager
+	membership   *model.Membership
 }
 
-func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryService {
+func NewCategoryService(categoryRepo repository.CategoryRepository, txManager repository.TxManager) CategoryService {
 	return &categoryService{
 		categoryRepo: categoryRepo,
+		txManager:    txManager,
+	}
+}
+
+func (s *categoryService) InWorkspace(membership model.Membership) CategoryService {
+	return &categoryService{
+		categoryRepo: s.categoryRepo.InWorkspace(membership.WorkspaceID),
+		txManager:    s.txManager.InWorkspace(membership.WorkspaceID),
+		membership:   &membership,
 	}
 }
 
-func (s *categoryService) CreateCategory(category *model.Category) error {
+func (s *categoryService) CreateCategory(ctx context.Context, category *model.Category) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
 	if category.Name == "" {
-		return errors.New("category name is required")
+		return fmt.Errorf("%w: category name is required", ErrInvalidCategory)
 	}
 
 	if category.UserID == uuid.Nil {
 		return errors.New("user ID is required")
 	}
 
-	return s.categoryRepo.Create(category)
+	return s.categoryRepo.Create(ctx, category)
 }
 
-func (s *categoryService) GetCategoryByID(id uuid.UUID) (*model.Category, error) {
-	return s.categoryRepo.GetByID(id)
+// GetCategoryByID returns a category of the user, or of their workspace when
+// their role lets them view it
+func (s *categoryService) GetCategoryByID(ctx context.Context, userID, id uuid.UUID) (*model.Category, error) {
+	return s.getCategory(ctx, userID, id, model.TaskPermissionView)
 }
 
-func (s *categoryService) GetCategoriesByUserID(userID uuid.UUID) ([]model.Category, error) {
-	return s.categoryRepo.GetByUserID(userID)
+// getCategory loads a category on which the user holds at least the
+// permission
+func (s *categoryService) getCategory(ctx context.Context, userID, id uuid.UUID, permission model.TaskPermission) (*model.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrCategoryNotFound)
+	}
+
+	if err := authorizeCategoryAccess(s.membership, userID, category, permission); err != nil {
+		return nil, err
+	}
+
+	return category, nil
 }
 
-func (s *categoryService) UpdateCategory(category *model.Category) error {
-	return s.categoryRepo.Update(category)
+func (s *categoryService) GetCategoriesByUserID(ctx context.Context, userID uuid.UUID) ([]model.Category, error) {
+	return s.categoryRepo.GetByUserID(ctx, userID)
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, userID uuid.UUID, category *model.Category) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	existing, err := s.getCategory(ctx, userID, category.ID, model.TaskPermissionOwner)
+	if err != nil {
+		return err
+	}
+
+	if category.Name == "" {
+		return fmt.Errorf("%w: category name is required", ErrInvalidCategory)
+	}
+
+	// Ownership and workspace cannot be changed through an update
+	category.UserID = existing.UserID
+	category.WorkspaceID = existing.WorkspaceID
+
+	return s.categoryRepo.Update(ctx, category)
 }
 
-func (s *categoryService) DeleteCategory(id uuid.UUID) error {
-	return s.categoryRepo.Delete(id)
+// DeleteCategory deletes a category and applies the policy to its tasks in
+// the same transaction, detaching them by default. Reassigning needs another
+// of the user's categories as the target. Tasks already in the trash keep
+// their category so they can be restored with it. Given a version, only the
+// category at that version is deleted.
+func (s *categoryService) DeleteCategory(ctx context.Context, userID, id uuid.UUID, version *int, policy model.CategoryDeletePolicy, targetID *uuid.UUID) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if _, err := s.getCategory(ctx, userID, id, model.TaskPermissionOwner); err != nil {
+		return err
+	}
+
+	if policy == "" {
+		policy = model.CategoryDeleteDetach
+	}
+	if !policy.IsValid() {
+		return fmt.Errorf("%w %q", ErrInvalidDeletePolicy, policy)
+	}
+
+	if policy == model.CategoryDeleteReassign {
+		if targetID == nil || *targetID == id {
+			return fmt.Errorf("%w: reassign needs another category as target", ErrInvalidDeletePolicy)
+		}
+		if _, err := s.GetCategoryByID(ctx, userID, *targetID); err != nil {
+			return fmt.Errorf("%w: target category not found", ErrInvalidDeletePolicy)
+		}
+	}
+
+	return s.txManager.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		if err := repos.Categories.Delete(ctx, id, version); err != nil {
+			return err
+		}
+
+		switch policy {
+		case model.CategoryDeleteReassign:
+			return repos.Tasks.MoveToCategory(ctx, id, targetID)
+		case model.CategoryDeleteTasks:
+			return repos.Tasks.DeleteByCategory(ctx, id)
+		default:
+			return repos.Tasks.MoveToCategory(ctx, id, nil)
+		}
+	})
 }
 
-func (s *categoryService) ListCategories(limit, offset int) ([]model.Category, error) {
-	return s.categoryRepo.List(limit, offset)
+func (s *categoryService) ListCategories(ctx context.Context, page model.PageRequest) (model.Page[model.Category], error) {
+	return s.categoryRepo.List(ctx, page)
 }
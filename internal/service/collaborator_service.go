@@ -0,0 +1,173 @@
+package service
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShareNotFound    = errors.New("share not found")
+	ErrAssigneeNotFound = errors.New("assignee not found")
+	ErrInvalidShare     = errors.New("invalid share")
+)
+
+// CollaboratorService manages who besides its owner works on a task. Anyone
+// who can view a task sees its collaborators; editors manage assignees and
+// only the owner shares the task. Collaborators must be members of the
+// task's workspace.
+type CollaboratorService interface {
+	GetShares(ctx context.Context, userID, taskID uuid.UUID) ([]model.TaskShare, error)
+	ShareTask(ctx context.Context, userID, taskID, withUserID uuid.UUID, permission model.TaskPermission) (*model.TaskShare, error)
+	UnshareTask(ctx context.Context, userID, taskID, withUserID uuid.UUID) error
+	GetAssignees(ctx context.Context, userID, taskID uuid.UUID) ([]model.User, error)
+	AssignTask(ctx context.Context, userID, taskID, assigneeID uuid.UUID) error
+	UnassignTask(ctx context.Context, userID, taskID, assigneeID uuid.UUID) error
+	// InWorkspace returns the service limited to the tasks of the
+	// membership's workspace
+	InWorkspace(membership model.Membership) CollaboratorService
+}
+
+type collaboratorService struct {
+	collaboratorRepo repository.TaskCollaboratorRepository
+	taskRepo         repository.TaskRepository
+	userRepo         repository.UserRepository
+	workspaceRepo    repository.WorkspaceRepository
+	membership       *model.Membership
+}
+
+func NewCollaboratorService(collaboratorRepo repository.TaskCollaboratorRepository, taskRepo repository.TaskRepository, userRepo repository.UserRepository, workspaceRepo repository.WorkspaceRepository) CollaboratorService {
+	return &collaboratorService{
+		collaboratorRepo: collaboratorRepo,
+		taskRepo:         taskRepo,
+		userRepo:         userRepo,
+		workspaceRepo:    workspaceRepo,
+	}
+}
+
+func (s *collaboratorService) InWorkspace(membership model.Membership) CollaboratorService {
+	scoped := *s
+	scoped.taskRepo = s.taskRepo.InWorkspace(membership.WorkspaceID)
+	scoped.membership = &membership
+	return &scoped
+}
+
+func (s *collaboratorService) GetShares(ctx context.Context, userID, taskID uuid.UUID) ([]model.TaskShare, error) {
+	if _, err := s.getTask(ctx, userID, taskID, model.TaskPermissionView); err != nil {
+		return nil, err
+	}
+
+	return s.collaboratorRepo.GetShares(ctx, taskID)
+}
+
+// ShareTask gives another user view or edit permission on one of the user's
+// tasks, changing the permission if the task is already shared with them
+func (s *collaboratorService) ShareTask(ctx context.Context, userID, taskID, withUserID uuid.UUID, permission model.TaskPermission) (*model.TaskShare, error) {
+	task, err := s.getTask(ctx, userID, taskID, model.TaskPermissionOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if !permission.IsShareable() {
+		return nil, fmt.Errorf("%w: permission must be view or edit", ErrInvalidShare)
+	}
+	if withUserID == task.UserID {
+		return nil, fmt.Errorf("%w: the owner already has access", ErrInvalidShare)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, withUserID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+	if err := s.checkMember(ctx, task, withUserID); err != nil {
+		return nil, err
+	}
+
+	share := &model.TaskShare{TaskID: taskID, UserID: withUserID, Permission: permission}
+	if err := s.collaboratorRepo.SaveShare(ctx, share); err != nil {
+		return nil, err
+	}
+	share.User = *user
+	return share, nil
+}
+
+func (s *collaboratorService) UnshareTask(ctx context.Context, userID, taskID, withUserID uuid.UUID) error {
+	if _, err := s.getTask(ctx, userID, taskID, model.TaskPermissionOwner); err != nil {
+		return err
+	}
+
+	removed, err := s.collaboratorRepo.DeleteShare(ctx, taskID, withUserID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+func (s *collaboratorService) GetAssignees(ctx context.Context, userID, taskID uuid.UUID) ([]model.User, error) {
+	if _, err := s.getTask(ctx, userID, taskID, model.TaskPermissionView); err != nil {
+		return nil, err
+	}
+
+	return s.collaboratorRepo.GetAssignees(ctx, taskID)
+}
+
+// AssignTask assigns a user to a task, which lets them edit it
+func (s *collaboratorService) AssignTask(ctx context.Context, userID, taskID, assigneeID uuid.UUID) error {
+	task, err := s.getTask(ctx, userID, taskID, model.TaskPermissionEdit)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, assigneeID); err != nil {
+		return notFoundAs(err, ErrUserNotFound)
+	}
+	if err := s.checkMember(ctx, task, assigneeID); err != nil {
+		return err
+	}
+
+	return s.collaboratorRepo.AddAssignee(ctx, taskID, assigneeID)
+}
+
+func (s *collaboratorService) UnassignTask(ctx context.Context, userID, taskID, assigneeID uuid.UUID) error {
+	if _, err := s.getTask(ctx, userID, taskID, model.TaskPermissionEdit); err != nil {
+		return err
+	}
+
+	removed, err := s.collaboratorRepo.RemoveAssignee(ctx, taskID, assigneeID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrAssigneeNotFound
+	}
+	return nil
+}
+
+// checkMember ensures a new collaborator belongs to the task's workspace
+func (s *collaboratorService) checkMember(ctx context.Context, task *model.Task, userID uuid.UUID) error {
+	_, err := s.workspaceRepo.GetMembership(ctx, task.WorkspaceID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: user is not a member of the task's workspace", ErrInvalidShare)
+	}
+	return err
+}
+
+// getTask loads a task on which the user holds at least the permission
+func (s *collaboratorService) getTask(ctx context.Context, userID, taskID uuid.UUID, permission model.TaskPermission) (*model.Task, error) {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrTaskNotFound)
+	}
+	if err := authorizeTaskAccess(s.membership, userID, task, permission); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
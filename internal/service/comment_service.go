@@ -0,0 +1,233 @@
+package service
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrNotCommentAuthor = errors.New("only the author can change a comment")
+	ErrInvalidComment   = errors.New("invalid comment")
+)
+
+// maxCommentLength bounds the body of a comment, in characters
+const maxCommentLength = 10000
+
+// maxCommentMentions bounds the distinct names looked up as mentions in one
+// comment; later names are left as plain text
+const maxCommentMentions = 20
+
+// mentionPattern matches @username where the @ does not follow a word
+// character, so e-mail addresses are not taken as mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.-]+)`)
+
+type CommentService interface {
+	CreateComment(ctx context.Context, comment *model.Comment) error
+	GetComments(ctx context.Context, userID, taskID uuid.UUID, page model.PageRequest) (model.Page[model.Comment], error)
+	UpdateComment(ctx context.Context, userID, taskID, id uuid.UUID, body string) (*model.Comment, error)
+	DeleteComment(ctx context.Context, userID, taskID, id uuid.UUID) error
+	GetCommentHistory(ctx context.Context, userID, taskID, id uuid.UUID) ([]model.CommentRevision, error)
+	// InWorkspace returns the service limited to the tasks of the
+	// membership's workspace
+	InWorkspace(membership model.Membership) CommentService
+}
+
+type commentService struct {
+	commentRepo   repository.CommentRepository
+	taskRepo      repository.TaskRepository
+	workspaceRepo repository.WorkspaceRepository
+	membership    *model.Membership
+}
+
+func NewCommentService(commentRepo repository.CommentRepository, taskRepo repository.TaskRepository, workspaceRepo repository.WorkspaceRepository) CommentService {
+	return &commentService{
+		commentRepo:   commentRepo,
+		taskRepo:      taskRepo,
+		workspaceRepo: workspaceRepo,
+	}
+}
+
+func (s *commentService) InWorkspace(membership model.Membership) CommentService {
+	scoped := *s
+	scoped.taskRepo = s.taskRepo.InWorkspace(membership.WorkspaceID)
+	scoped.membership = &membership
+	return &scoped
+}
+
+// CreateComment adds a comment by comment.UserID to one of their tasks
+func (s *commentService) CreateComment(ctx context.Context, comment *model.Comment) error {
+	task, err := s.checkTask(ctx, comment.UserID, comment.TaskID)
+	if err != nil {
+		return err
+	}
+
+	body, err := validateCommentBody(comment.Body)
+	if err != nil {
+		return err
+	}
+	comment.Body = body
+
+	if comment.Mentions, err = s.resolveMentions(ctx, task.WorkspaceID, body); err != nil {
+		return err
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		return err
+	}
+
+	// Reload to include the author
+	created, err := s.commentRepo.GetByID(ctx, comment.ID)
+	if err != nil {
+		return err
+	}
+	*comment = *created
+	return nil
+}
+
+func (s *commentService) GetComments(ctx context.Context, userID, taskID uuid.UUID, page model.PageRequest) (model.Page[model.Comment], error) {
+	if _, err := s.checkTask(ctx, userID, taskID); err != nil {
+		return model.Page[model.Comment]{}, err
+	}
+
+	return s.commentRepo.ListByTask(ctx, taskID, page)
+}
+
+// UpdateComment replaces the body of a comment, keeping the previous body
+// in its history. Only the author can edit a comment.
+func (s *commentService) UpdateComment(ctx context.Context, userID, taskID, id uuid.UUID, body string) (*model.Comment, error) {
+	comment, task, err := s.getAuthoredComment(ctx, userID, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if body == comment.Body {
+		return comment, nil
+	}
+
+	revision := &model.CommentRevision{CommentID: comment.ID, Body: comment.Body}
+
+	now := time.Now()
+	comment.Body = body
+	comment.EditedAt = &now
+	if comment.Mentions, err = s.resolveMentions(ctx, task.WorkspaceID, body); err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.Update(ctx, comment, revision); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, userID, taskID, id uuid.UUID) error {
+	if _, _, err := s.getAuthoredComment(ctx, userID, taskID, id); err != nil {
+		return err
+	}
+
+	return s.commentRepo.Delete(ctx, id)
+}
+
+// GetCommentHistory returns the previous bodies of a comment, oldest first
+func (s *commentService) GetCommentHistory(ctx context.Context, userID, taskID, id uuid.UUID) ([]model.CommentRevision, error) {
+	if _, _, err := s.getComment(ctx, userID, taskID, id); err != nil {
+		return nil, err
+	}
+
+	return s.commentRepo.GetRevisions(ctx, id)
+}
+
+// checkTask loads the task and ensures it is visible to the user. Anyone
+// who can view a task can take part in its discussion.
+func (s *commentService) checkTask(ctx context.Context, userID, taskID uuid.UUID) (*model.Task, error) {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrTaskNotFound)
+	}
+	if err := authorizeTaskAccess(s.membership, userID, task, model.TaskPermissionView); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// getComment loads a comment of the given task, which must be visible to
+// the user, together with the task
+func (s *commentService) getComment(ctx context.Context, userID, taskID, id uuid.UUID) (*model.Comment, *model.Task, error) {
+	task, err := s.checkTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, notFoundAs(err, ErrCommentNotFound)
+	}
+	if comment.TaskID != taskID {
+		return nil, nil, ErrCommentNotFound
+	}
+	return comment, task, nil
+}
+
+func (s *commentService) getAuthoredComment(ctx context.Context, userID, taskID, id uuid.UUID) (*model.Comment, *model.Task, error) {
+	comment, task, err := s.getComment(ctx, userID, taskID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if comment.UserID != userID {
+		return nil, nil, ErrNotCommentAuthor
+	}
+	return comment, task, nil
+}
+
+// resolveMentions looks up the users mentioned in a comment body among the
+// members of the task's workspace. Names that do not belong to a member, and
+// names past the first maxCommentMentions, are left as plain text.
+func (s *commentService) resolveMentions(ctx context.Context, workspaceID uuid.UUID, body string) ([]model.UserSummary, error) {
+	seen := map[string]bool{}
+	var users []model.UserSummary
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// Trailing punctuation ends the sentence rather than the username
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		if len(seen) == maxCommentMentions {
+			break
+		}
+		seen[username] = true
+
+		user, err := s.workspaceRepo.GetMemberByUsername(ctx, workspaceID, username)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, nil
+}
+
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("%w: body is required", ErrInvalidComment)
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return "", fmt.Errorf("%w: body is longer than %d characters", ErrInvalidComment, maxCommentLength)
+	}
+	return body, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"Arise-test/configs"
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used for a different request")
+	ErrRequestInProgress     = errors.New("a request with this idempotency key is still in progress")
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header, in bytes
+const maxIdempotencyKeyLength = 255
+
+// idempotencyLockTimeout is how long a request may hold its key before a
+// retry assumes it was lost, e.g. to a crash, and handles the request again
+const idempotencyLockTimeout = time.Minute
+
+type IdempotencyService interface {
+	// Begin reserves the key for a request with the given fingerprint. It
+	// returns the stored response when the key was already used for the same
+	// request, and nil when the request should be handled and then completed
+	// or released.
+	Begin(ctx context.Context, userID uuid.UUID, key, fingerprint string) (*model.IdempotencyKey, error)
+	// Complete stores the response to replay to retries of the request
+	Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, headers map[string]string, body []byte) error
+	// Release frees the key of a request that failed, so it can be retried
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+	// PurgeExpired deletes the keys older than the configured window and
+	// reports how many there were
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type idempotencyService struct {
+	idempotencyRepo repository.IdempotencyRepository
+	ttl             time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyRepository, config configs.IdempotencyConfig) IdempotencyService {
+	return &idempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             config.TTL,
+	}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, userID uuid.UUID, key, fingerprint string) (*model.IdempotencyKey, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%w: must be 1 to %d characters", ErrInvalidIdempotencyKey, maxIdempotencyKeyLength)
+	}
+
+	now := time.Now()
+	reserved, err := s.idempotencyRepo.Reserve(ctx, &model.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	if err != nil || reserved {
+		return nil, err
+	}
+
+	existing, err := s.idempotencyRepo.Get(ctx, userID, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Released in the meantime
+		return nil, ErrRequestInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pending := existing.StatusCode == 0
+	if existing.ExpiresAt.Before(now) || (pending && existing.CreatedAt.Before(now.Add(-idempotencyLockTimeout))) {
+		// The key is free again; start over as if it had not been used
+		if err := s.idempotencyRepo.Delete(ctx, userID, key); err != nil {
+			return nil, err
+		}
+		return s.Begin(ctx, userID, key, fingerprint)
+	}
+
+	if existing.RequestHash != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if pending {
+		return nil, ErrRequestInProgress
+	}
+	return existing, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, headers map[string]string, body []byte) error {
+	return s.idempotencyRepo.Complete(ctx, userID, key, statusCode, headers, body)
+}
+
+func (s *idempotencyService) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	return s.idempotencyRepo.Delete(ctx, userID, key)
+}
+
+func (s *idempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.idempotencyRepo.DeleteExpired(ctx, time.Now())
+}
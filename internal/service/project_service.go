@@ -0,0 +1,322 @@
+package service
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMilestoneNotFound = errors.New("milestone not found")
+	ErrInvalidProject    = errors.New("invalid project")
+)
+
+// Progress counts the open and completed tasks of a project or milestone.
+// Cancelled tasks are not counted.
+type Progress struct {
+	Open       int64   `json:"open"`
+	Completed  int64   `json:"completed"`
+	Total      int64   `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+// MilestoneProgress is the progress of one milestone
+type MilestoneProgress struct {
+	MilestoneID uuid.UUID `json:"milestone_id"`
+	Name        string    `json:"name"`
+	Progress
+}
+
+// ProjectProgress is the progress of a whole project and of each of its
+// milestones
+type ProjectProgress struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Progress
+	Milestones []MilestoneProgress `json:"milestones"`
+}
+
+type ProjectService interface {
+	CreateProject(ctx context.Context, project *model.Project) error
+	GetProjectByID(ctx context.Context, userID, id uuid.UUID) (*model.Project, error)
+	GetProjectsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Project, error)
+	UpdateProject(ctx context.Context, userID uuid.UUID, project *model.Project) error
+	DeleteProject(ctx context.Context, userID, id uuid.UUID) error
+	CreateMilestone(ctx context.Context, userID uuid.UUID, milestone *model.Milestone) error
+	GetMilestone(ctx context.Context, userID, projectID, id uuid.UUID) (*model.Milestone, error)
+	UpdateMilestone(ctx context.Context, userID uuid.UUID, milestone *model.Milestone) error
+	DeleteMilestone(ctx context.Context, userID, projectID, id uuid.UUID) error
+	GetProjectProgress(ctx context.Context, userID, id uuid.UUID) (*ProjectProgress, error)
+	GetMilestoneProgress(ctx context.Context, userID, projectID, id uuid.UUID) (*MilestoneProgress, error)
+	// InWorkspace returns the service limited to the projects of the
+	// membership's workspace, which guests can only read
+	InWorkspace(membership model.Membership) ProjectService
+}
+
+type projectService struct {
+	projectRepo repository.ProjectRepository
+	membership  *model.Membership
+}
+
+func NewProjectService(projectRepo repository.ProjectRepository) ProjectService {
+	return &projectService{
+		projectRepo: projectRepo,
+	}
+}
+
+func (s *projectService) InWorkspace(membership model.Membership) ProjectService {
+	return &projectService{
+		projectRepo: s.projectRepo.InWorkspace(membership.WorkspaceID),
+		membership:  &membership,
+	}
+}
+
+func (s *projectService) CreateProject(ctx context.Context, project *model.Project) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if project.UserID == uuid.Nil {
+		return errors.New("user ID is required")
+	}
+
+	if project.Status == "" {
+		project.Status = model.ProjectStatusPlanned
+	}
+	if err := validateProject(project); err != nil {
+		return err
+	}
+
+	return s.projectRepo.Create(ctx, project)
+}
+
+// GetProjectByID returns one of the user's projects with its milestones
+func (s *projectService) GetProjectByID(ctx context.Context, userID, id uuid.UUID) (*model.Project, error) {
+	project, err := s.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrProjectNotFound)
+	}
+
+	if err := authorizeProject(userID, project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (s *projectService) GetProjectsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Project, error) {
+	return s.projectRepo.GetByUserID(ctx, userID)
+}
+
+func (s *projectService) UpdateProject(ctx context.Context, userID uuid.UUID, project *model.Project) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	existing, err := s.GetProjectByID(ctx, userID, project.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateProject(project); err != nil {
+		return err
+	}
+
+	// Ownership and workspace cannot be changed through an update
+	project.UserID = existing.UserID
+	project.WorkspaceID = existing.WorkspaceID
+	project.UpdatedAt = time.Now()
+
+	return s.projectRepo.Update(ctx, project)
+}
+
+// DeleteProject deletes a project and its milestones. Its tasks are kept but
+// no longer belong to a project.
+func (s *projectService) DeleteProject(ctx context.Context, userID, id uuid.UUID) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if _, err := s.GetProjectByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.projectRepo.Delete(ctx, id)
+}
+
+func (s *projectService) CreateMilestone(ctx context.Context, userID uuid.UUID, milestone *model.Milestone) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if _, err := s.GetProjectByID(ctx, userID, milestone.ProjectID); err != nil {
+		return err
+	}
+
+	if err := validateMilestone(milestone); err != nil {
+		return err
+	}
+
+	return s.projectRepo.CreateMilestone(ctx, milestone)
+}
+
+func (s *projectService) UpdateMilestone(ctx context.Context, userID uuid.UUID, milestone *model.Milestone) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	existing, err := s.GetMilestone(ctx, userID, milestone.ProjectID, milestone.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateMilestone(milestone); err != nil {
+		return err
+	}
+
+	milestone.CreatedAt = existing.CreatedAt
+	milestone.UpdatedAt = time.Now()
+	return s.projectRepo.UpdateMilestone(ctx, milestone)
+}
+
+// DeleteMilestone deletes a milestone; its tasks stay in the project
+func (s *projectService) DeleteMilestone(ctx context.Context, userID, projectID, id uuid.UUID) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if _, err := s.GetProjectByID(ctx, userID, projectID); err != nil {
+		return err
+	}
+
+	removed, err := s.projectRepo.DeleteMilestone(ctx, projectID, id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrMilestoneNotFound
+	}
+	return nil
+}
+
+// GetProjectProgress counts the open and completed tasks of the project, in
+// total and per milestone
+func (s *projectService) GetProjectProgress(ctx context.Context, userID, id uuid.UUID) (*ProjectProgress, error) {
+	project, err := s.GetProjectByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.projectRepo.CountTasks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProjectProgress{ProjectID: id, Milestones: []MilestoneProgress{}}
+	byMilestone := map[uuid.UUID]*Progress{}
+	for _, milestone := range project.Milestones {
+		byMilestone[milestone.ID] = &Progress{}
+	}
+	for _, count := range counts {
+		result.add(count.Status, count.Count)
+		if count.MilestoneID != nil {
+			if progress, ok := byMilestone[*count.MilestoneID]; ok {
+				progress.add(count.Status, count.Count)
+			}
+		}
+	}
+
+	result.finish()
+	for _, milestone := range project.Milestones {
+		progress := byMilestone[milestone.ID]
+		progress.finish()
+		result.Milestones = append(result.Milestones, MilestoneProgress{
+			MilestoneID: milestone.ID,
+			Name:        milestone.Name,
+			Progress:    *progress,
+		})
+	}
+	return result, nil
+}
+
+func (s *projectService) GetMilestoneProgress(ctx context.Context, userID, projectID, id uuid.UUID) (*MilestoneProgress, error) {
+	milestone, err := s.GetMilestone(ctx, userID, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.projectRepo.CountTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MilestoneProgress{MilestoneID: id, Name: milestone.Name}
+	for _, count := range counts {
+		if count.MilestoneID != nil && *count.MilestoneID == id {
+			result.add(count.Status, count.Count)
+		}
+	}
+	result.finish()
+	return result, nil
+}
+
+// GetMilestone returns a milestone of one of the user's projects
+func (s *projectService) GetMilestone(ctx context.Context, userID, projectID, id uuid.UUID) (*model.Milestone, error) {
+	if _, err := s.GetProjectByID(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	milestone, err := s.projectRepo.GetMilestone(ctx, projectID, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrMilestoneNotFound)
+	}
+	return milestone, nil
+}
+
+// add counts tasks with the status
+func (p *Progress) add(status model.TaskStatus, count int64) {
+	switch status {
+	case model.TaskStatusCompleted:
+		p.Completed += count
+	case model.TaskStatusCancelled:
+		return
+	default:
+		p.Open += count
+	}
+	p.Total += count
+}
+
+// finish computes the completed percentage
+func (p *Progress) finish() {
+	if p.Total > 0 {
+		p.Percentage = float64(p.Completed) * 100 / float64(p.Total)
+	}
+}
+
+// validateProject checks the name, status and dates of a project
+func validateProject(project *model.Project) error {
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProject)
+	}
+	if !project.Status.IsValid() {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidProject, project.Status)
+	}
+	if project.StartDate != nil && project.TargetDate != nil && project.TargetDate.Before(*project.StartDate) {
+		return fmt.Errorf("%w: target date is before start date", ErrInvalidProject)
+	}
+	return nil
+}
+
+func validateMilestone(milestone *model.Milestone) error {
+	milestone.Name = strings.TrimSpace(milestone.Name)
+	if milestone.Name == "" {
+		return fmt.Errorf("%w: milestone name is required", ErrInvalidProject)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleInUse         = errors.New("role is assigned to users")
+	ErrLastAdmin         = errors.New("cannot remove the last admin")
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+// builtinRoles are always available and cannot be changed or deleted
+var builtinRoles = map[string][]model.Permission{
+	model.RoleUser:  {},
+	model.RoleAdmin: model.AllPermissions,
+}
+
+type RoleService interface {
+	CreateRole(ctx context.Context, role *model.Role) error
+	GetRoleByID(ctx context.Context, id uuid.UUID) (*model.Role, error)
+	ListRoles(ctx context.Context) ([]model.Role, error)
+	UpdateRole(ctx context.Context, role *model.Role) error
+	DeleteRole(ctx context.Context, id uuid.UUID) error
+	AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error
+	HasPermission(ctx context.Context, userID uuid.UUID, permission model.Permission) (bool, error)
+}
+
+type roleService struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+}
+
+func NewRoleService(roleRepo repository.RoleRepository, userRepo repository.UserRepository) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *roleService) CreateRole(ctx context.Context, role *model.Role) error {
+	if role.Name == "" {
+		return errors.New("role name is required")
+	}
+
+	if _, ok := builtinRoles[role.Name]; ok {
+		return errors.New("role name is reserved")
+	}
+
+	if err := validatePermissions(role.Permissions); err != nil {
+		return err
+	}
+
+	if _, err := s.roleRepo.GetByName(ctx, role.Name); err == nil {
+		return errors.New("role with this name already exists")
+	}
+
+	return s.roleRepo.Create(ctx, role)
+}
+
+func (s *roleService) GetRoleByID(ctx context.Context, id uuid.UUID) (*model.Role, error) {
+	role, err := s.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrRoleNotFound)
+	}
+	return role, nil
+}
+
+// ListRoles returns the built-in roles followed by the custom roles
+func (s *roleService) ListRoles(ctx context.Context) ([]model.Role, error) {
+	custom, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []model.Role{
+		{Name: model.RoleUser, Description: "Built-in role", Permissions: builtinRoles[model.RoleUser]},
+		{Name: model.RoleAdmin, Description: "Built-in role", Permissions: builtinRoles[model.RoleAdmin]},
+	}
+	return append(roles, custom...), nil
+}
+
+func (s *roleService) UpdateRole(ctx context.Context, role *model.Role) error {
+	existing, err := s.GetRoleByID(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+
+	// Users reference roles by name, so the name is fixed once created
+	role.Name = existing.Name
+
+	if err := validatePermissions(role.Permissions); err != nil {
+		return err
+	}
+
+	return s.roleRepo.Update(ctx, role)
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	role, err := s.GetRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.roleRepo.CountUsers(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+
+	return s.roleRepo.Delete(ctx, id)
+}
+
+func (s *roleService) AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return notFoundAs(err, ErrUserNotFound)
+	}
+
+	if _, err := s.permissionsFor(ctx, roleName); err != nil {
+		return err
+	}
+
+	if user.Role == model.RoleAdmin && roleName != model.RoleAdmin {
+		admins, err := s.roleRepo.CountUsers(ctx, model.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return ErrLastAdmin
+		}
+	}
+
+	user.Role = roleName
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *roleService) HasPermission(ctx context.Context, userID uuid.UUID, permission model.Permission) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, notFoundAs(err, ErrUserNotFound)
+	}
+
+	permissions, err := s.permissionsFor(ctx, user.Role)
+	if err != nil {
+		// A user left with a deleted custom role has no permissions
+		if errors.Is(err, ErrRoleNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, p := range permissions {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *roleService) permissionsFor(ctx context.Context, roleName string) ([]model.Permission, error) {
+	if permissions, ok := builtinRoles[roleName]; ok {
+		return permissions, nil
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, roleName)
+	if err != nil {
+		return nil, notFoundAs(err, ErrRoleNotFound)
+	}
+	return role.Permissions, nil
+}
+
+func validatePermissions(permissions []model.Permission) error {
+	for _, p := range permissions {
+		if !p.IsValid() {
+			return fmt.Errorf("%w %q", ErrUnknownPermission, p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("a tag with this name already exists")
+	ErrInvalidTag  = errors.New("invalid tag")
+)
+
+// maxTagNameLength bounds the name of a tag, in characters
+const maxTagNameLength = 50
+
+type TagService interface {
+	CreateTag(ctx context.Context, tag *model.Tag) error
+	GetTagsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Tag, error)
+	UpdateTag(ctx context.Context, userID uuid.UUID, tag *model.Tag) error
+	DeleteTag(ctx context.Context, userID, id uuid.UUID) error
+	MergeTags(ctx context.Context, userID, sourceID, targetID uuid.UUID) (*model.Tag, error)
+	AddTagsToTask(ctx context.Context, userID, taskID uuid.UUID, tagIDs []uuid.UUID) error
+	RemoveTagFromTask(ctx context.Context, userID, taskID, tagID uuid.UUID) error
+	// InWorkspace returns the service limited to the tasks of the
+	// membership's workspace
+	InWorkspace(membership model.Membership) TagService
+}
+
+type tagService struct {
+	tagRepo  repository.TagRepository
+	taskRepo repository.TaskRepository
+}
+
+func NewTagService(tagRepo repository.TagRepository, taskRepo repository.TaskRepository) TagService {
+	return &tagService{
+		tagRepo:  tagRepo,
+		taskRepo: taskRepo,
+	}
+}
+
+func (s *tagService) InWorkspace(membership model.Membership) TagService {
+	scoped := *s
+	scoped.taskRepo = s.taskRepo.InWorkspace(membership.WorkspaceID)
+	return &scoped
+}
+
+func (s *tagService) CreateTag(ctx context.Context, tag *model.Tag) error {
+	name, err := validateTagName(tag.Name)
+	if err != nil {
+		return err
+	}
+	tag.Name = name
+
+	if err := s.checkNameFree(ctx, tag.UserID, uuid.Nil, name); err != nil {
+		return err
+	}
+
+	return s.tagRepo.Create(ctx, tag)
+}
+
+// GetTagsByUserID returns the user's tags with their usage counts
+func (s *tagService) GetTagsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Tag, error) {
+	return s.tagRepo.GetByUserID(ctx, userID)
+}
+
+// UpdateTag renames or recolors a tag. Renaming to the name of another tag is
+// rejected; merge the tags instead.
+func (s *tagService) UpdateTag(ctx context.Context, userID uuid.UUID, tag *model.Tag) error {
+	existing, err := s.getTag(ctx, userID, tag.ID)
+	if err != nil {
+		return err
+	}
+
+	name, err := validateTagName(tag.Name)
+	if err != nil {
+		return err
+	}
+	if err := s.checkNameFree(ctx, userID, tag.ID, name); err != nil {
+		return err
+	}
+
+	existing.Name = name
+	existing.Color = tag.Color
+	if err := s.tagRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+	*tag = *existing
+	return nil
+}
+
+func (s *tagService) DeleteTag(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getTag(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.tagRepo.Delete(ctx, id)
+}
+
+// MergeTags moves the tasks of the source tag to the target tag and deletes
+// the source tag, returning the target
+func (s *tagService) MergeTags(ctx context.Context, userID, sourceID, targetID uuid.UUID) (*model.Tag, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: cannot merge a tag into itself", ErrInvalidTag)
+	}
+	if _, err := s.getTag(ctx, userID, sourceID); err != nil {
+		return nil, err
+	}
+	target, err := s.getTag(ctx, userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.Merge(ctx, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// AddTagsToTask tags one of the user's tasks with some of their tags
+func (s *tagService) AddTagsToTask(ctx context.Context, userID, taskID uuid.UUID, tagIDs []uuid.UUID) error {
+	if err := s.checkTask(ctx, userID, taskID); err != nil {
+		return err
+	}
+	if len(tagIDs) == 0 {
+		return fmt.Errorf("%w: no tags given", ErrInvalidTag)
+	}
+	for _, tagID := range tagIDs {
+		if _, err := s.getTag(ctx, userID, tagID); err != nil {
+			return err
+		}
+	}
+
+	return s.tagRepo.AddToTask(ctx, taskID, tagIDs)
+}
+
+func (s *tagService) RemoveTagFromTask(ctx context.Context, userID, taskID, tagID uuid.UUID) error {
+	if err := s.checkTask(ctx, userID, taskID); err != nil {
+		return err
+	}
+
+	removed, err := s.tagRepo.RemoveFromTask(ctx, taskID, tagID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// checkTask ensures the task exists and is visible to the user
+func (s *tagService) checkTask(ctx context.Context, userID, taskID uuid.UUID) error {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return notFoundAs(err, ErrTaskNotFound)
+	}
+	return authorizeTask(userID, task)
+}
+
+// getTag loads a tag, which must belong to the user
+func (s *tagService) getTag(ctx context.Context, userID, id uuid.UUID) (*model.Tag, error) {
+	tag, err := s.tagRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrTagNotFound)
+	}
+	if tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+// checkNameFree ensures no other tag of the user has the name, ignoring case
+func (s *tagService) checkNameFree(ctx context.Context, userID, id uuid.UUID, name string) error {
+	existing, err := s.tagRepo.GetByName(ctx, userID, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != id {
+		return ErrTagExists
+	}
+	return nil
+}
+
+// validateTagName trims a tag name and checks its length
+func validateTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidTag)
+	}
+	if len([]rune(name)) > maxTagNameLength {
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidTag, maxTagNameLength)
+	}
+	return name, nil
+}
@@ -1,84 +1,1131 @@
 package service
 
 import (
+	"Arise-test/configs"
 	"Arise-test/internal/model"
+	"Arise-test/internal/recurrence"
 	"Arise-test/internal/repository"
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrParentTaskNotFound = errors.New("parent task not found")
+	ErrTaskCycle          = errors.New("a task cannot be nested under itself or its subtasks")
+	ErrOpenSubtasks       = errors.New("task has open subtasks")
+	ErrTaskBlocked        = errors.New("task is blocked by open dependencies")
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	ErrDependencyExists   = errors.New("dependency already exists")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrInvalidRecurrence  = errors.New("invalid recurrence rule")
+	ErrInvalidStatus      = errors.New("invalid task status")
+	ErrInvalidTransition  = errors.New("status transition not allowed")
+	ErrInvalidFilter      = errors.New("invalid task filter")
+	ErrInvalidTask        = errors.New("invalid task")
+	ErrInvalidBulk        = errors.New("invalid bulk request")
+	ErrBulkAborted        = errors.New("not applied because another item failed")
+)
+
+// maxBulkTasks bounds the number of tasks a bulk request can change
+const maxBulkTasks = 100
+
+// TaskTree is a task with its nested subtasks and the share of its
+// descendants that are completed, as a percentage
+type TaskTree struct {
+	model.Task
+	Progress float64     `json:"progress"`
+	Children []*TaskTree `json:"children"`
+}
+
+// BulkTarget selects the tasks of a bulk update or delete: the listed IDs or,
+// without IDs, the user's tasks matching Filter
+type BulkTarget struct {
+	IDs    []uuid.UUID
+	Filter *model.TaskFilter
+}
+
+// BulkResult is the outcome of one item of a bulk request. Err is nil when
+// the item was applied, and Task is the created or updated task.
+type BulkResult struct {
+	ID   uuid.UUID
+	Task *model.Task
+	Err  error
+}
+
 type TaskService interface {
-	CreateTask(task *model.Task) error
-	GetTaskByID(id uuid.UUID) (*model.Task, error)
-	GetTasksByUserID(userID uuid.UUID, limit, offset int) ([]model.Task, error)
-	GetTasksByStatus(userID uuid.UUID, status model.TaskStatus, limit, offset int) ([]model.Task, error)
-	GetTasksByCategory(categoryID uuid.UUID, limit, offset int) ([]model.Task, error)
-	UpdateTask(task *model.Task) error
-	UpdateTaskStatus(id uuid.UUID, status model.TaskStatus) error
-	DeleteTask(id uuid.UUID) error
-	ListTasks(limit, offset int) ([]model.Task, error)
+	CreateTask(ctx context.Context, task *model.Task) error
+	GetTaskByID(ctx context.Context, userID, id uuid.UUID) (*model.Task, error)
+	GetTasksByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Task, error)
+	FindTasks(ctx context.Context, userID uuid.UUID, filter model.TaskFilter, page model.PageRequest) (model.Page[model.Task], error)
+	FindAssignedTasks(ctx context.Context, userID uuid.UUID, filter model.TaskFilter, page model.PageRequest) (model.Page[model.Task], error)
+	GetTasksByCategory(ctx context.Context, userID, categoryID uuid.UUID, limit, offset int) ([]model.Task, error)
+	UpdateTask(ctx context.Context, userID uuid.UUID, task *model.Task) error
+	// UpdateTaskStatus, MoveTask and DeleteTask only apply to the task at the
+	// given version; a nil version applies to any
+	UpdateTaskStatus(ctx context.Context, userID, id uuid.UUID, version *int, status model.TaskStatus, reason string, force bool) error
+	MoveTask(ctx context.Context, userID, id uuid.UUID, version *int, columnID uuid.UUID, afterID *uuid.UUID) (*model.Task, error)
+	GetStatusHistory(ctx context.Context, userID, id uuid.UUID) ([]model.TaskStatusChange, error)
+	GetSubtasks(ctx context.Context, userID, id uuid.UUID) ([]model.Task, error)
+	GetTaskTree(ctx context.Context, userID, id uuid.UUID) (*TaskTree, error)
+	GetDependencies(ctx context.Context, userID, id uuid.UUID) ([]model.Task, error)
+	AddDependency(ctx context.Context, userID, id, dependsOnID uuid.UUID) error
+	RemoveDependency(ctx context.Context, userID, id, dependsOnID uuid.UUID) error
+	DeleteTask(ctx context.Context, userID, id uuid.UUID, version *int) error
+	// BulkCreateTasks, BulkUpdateTasks and BulkDeleteTasks apply a change to
+	// many tasks in one transaction and report the outcome per task. When
+	// atomic, a failing item leaves every task unchanged; otherwise the
+	// items that succeed are kept.
+	BulkCreateTasks(ctx context.Context, userID uuid.UUID, tasks []*model.Task, atomic bool) ([]BulkResult, error)
+	BulkUpdateTasks(ctx context.Context, userID uuid.UUID, target BulkTarget, change func(task *model.Task), atomic bool) ([]BulkResult, error)
+	BulkDeleteTasks(ctx context.Context, userID uuid.UUID, target BulkTarget, atomic bool) ([]BulkResult, error)
+	ListTasks(ctx context.Context, page model.PageRequest) (model.Page[model.Task], error)
+	// InWorkspace returns the service limited to the tasks and categories of
+	// the membership's workspace
+	InWorkspace(membership model.Membership) TaskService
 }
 
 type taskService struct {
-	taskRepo repository.TaskRepository
+	taskRepo         repository.TaskRepository
+	categoryRepo     repository.CategoryRepository
+	projectRepo      repository.ProjectRepository
+	boardRepo        repository.BoardRepository
+	dependencyRepo   repository.TaskDependencyRepository
+	statusChangeRepo repository.TaskStatusChangeRepository
+	collaboratorRepo repository.TaskCollaboratorRepository
+	txManager        repository.TxManager
+	transitions      map[model.TaskStatus][]model.TaskStatus
+	membership       *model.Membership
 }
 
-func NewTaskService(taskRepo repository.TaskRepository) TaskService {
+// NewTaskService creates a task service. A config without status transitions
+// uses configs.DefaultStatusTransitions.
+func NewTaskService(taskRepo repository.TaskRepository, categoryRepo repository.CategoryRepository, projectRepo repository.ProjectRepository, boardRepo repository.BoardRepository, dependencyRepo repository.TaskDependencyRepository, statusChangeRepo repository.TaskStatusChangeRepository, collaboratorRepo repository.TaskCollaboratorRepository, txManager repository.TxManager, config configs.TaskConfig) TaskService {
+	transitions := config.StatusTransitions
+	if len(transitions) == 0 {
+		transitions, _ = configs.ParseStatusTransitions(configs.DefaultStatusTransitions)
+	}
+
 	return &taskService{
-		taskRepo: taskRepo,
+		taskRepo:         taskRepo,
+		categoryRepo:     categoryRepo,
+		projectRepo:      projectRepo,
+		boardRepo:        boardRepo,
+		dependencyRepo:   dependencyRepo,
+		statusChangeRepo: statusChangeRepo,
+		collaboratorRepo: collaboratorRepo,
+		txManager:        txManager,
+		transitions:      transitions,
 	}
 }
 
-func (s *taskService) CreateTask(task *model.Task) error {
-	if task.Title == "" {
-		return errors.New("task title is required")
+func (s *taskService) InWorkspace(membership model.Membership) TaskService {
+	scoped := *s
+	scoped.taskRepo = s.taskRepo.InWorkspace(membership.WorkspaceID)
+	scoped.categoryRepo = s.categoryRepo.InWorkspace(membership.WorkspaceID)
+	scoped.projectRepo = s.projectRepo.InWorkspace(membership.WorkspaceID)
+	scoped.boardRepo = s.boardRepo.InWorkspace(membership.WorkspaceID)
+	scoped.txManager = s.txManager.InWorkspace(membership.WorkspaceID)
+	scoped.membership = &membership
+	return &scoped
+}
+
+// inTransaction runs fn with a copy of the service whose task, category,
+// board, status history and collaborator repositories are bound to one
+// transaction
+func (s *taskService) inTransaction(ctx context.Context, fn func(tx *taskService) error) error {
+	return s.txManager.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		tx := *s
+		tx.taskRepo = repos.Tasks
+		tx.categoryRepo = repos.Categories
+		tx.boardRepo = repos.Boards
+		tx.statusChangeRepo = repos.StatusChanges
+		tx.collaboratorRepo = repos.Collaborators
+		return fn(&tx)
+	})
+}
+
+func (s *taskService) CreateTask(ctx context.Context, task *model.Task) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	if err := s.checkNew(ctx, task); err != nil {
+		return err
+	}
+
+	return s.taskRepo.Create(ctx, task)
+}
+
+// checkNew validates a task before it is created, defaulting its status and
+// deriving its project from its milestone
+func (s *taskService) checkNew(ctx context.Context, task *model.Task) error {
+	if err := checkFields(task); err != nil {
+		return err
 	}
 
 	if task.UserID == uuid.Nil {
 		return errors.New("user ID is required")
 	}
 
-	return s.taskRepo.Create(task)
+	if task.Status == "" {
+		task.Status = model.TaskStatusPending
+	}
+	if !task.Status.IsValid() {
+		return fmt.Errorf("%w %q", ErrInvalidStatus, task.Status)
+	}
+
+	if _, err := s.checkCategory(ctx, task.UserID, task.CategoryID); err != nil {
+		return err
+	}
+
+	projectID, err := s.checkProject(ctx, task.UserID, task.ProjectID, task.MilestoneID)
+	if err != nil {
+		return err
+	}
+	task.ProjectID = projectID
+
+	if err := s.checkParent(ctx, task.UserID, task.ID, task.ParentID); err != nil {
+		return err
+	}
+
+	return checkRecurrence(task)
 }
 
-func (s *taskService) GetTaskByID(id uuid.UUID) (*model.Task, error) {
-	return s.taskRepo.GetByID(id)
+// GetTaskByID returns a task the user owns, is assigned to or was shared,
+// or that their workspace role lets them view
+func (s *taskService) GetTaskByID(ctx context.Context, userID, id uuid.UUID) (*model.Task, error) {
+	return s.getTask(ctx, userID, id, model.TaskPermissionView)
 }
 
-func (s *taskService) GetTasksByUserID(userID uuid.UUID, limit, offset int) ([]model.Task, error) {
-	return s.taskRepo.GetByUserID(userID, limit, offset)
+// getTask loads a task on which the user holds at least the permission
+func (s *taskService) getTask(ctx context.Context, userID, id uuid.UUID, permission model.TaskPermission) (*model.Task, error) {
+	task, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrTaskNotFound)
+	}
+
+	if err := authorizeTaskAccess(s.membership, userID, task, permission); err != nil {
+		return nil, err
+	}
+
+	tasks := []model.Task{*task}
+	if err := s.markBlocked(ctx, tasks); err != nil {
+		return nil, err
+	}
+	task.Blocked = tasks[0].Blocked
+
+	return task, nil
 }
 
-func (s *taskService) GetTasksByStatus(userID uuid.UUID, status model.TaskStatus, limit, offset int) ([]model.Task, error) {
-	return s.taskRepo.GetByStatus(userID, status, limit, offset)
+func (s *taskService) GetTasksByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Task, error) {
+	tasks, err := s.taskRepo.GetByUserID(ctx, userID, limit, offset)
+	return s.withBlocked(ctx, tasks, err)
 }
 
-func (s *taskService) GetTasksByCategory(categoryID uuid.UUID, limit, offset int) ([]model.Task, error) {
-	return s.taskRepo.GetByCategory(categoryID, limit, offset)
+// FindTasks returns a page of the user's tasks matching the filter
+func (s *taskService) FindTasks(ctx context.Context, userID uuid.UUID, filter model.TaskFilter, page model.PageRequest) (model.Page[model.Task], error) {
+	var result model.Page[model.Task]
+
+	if err := validateTaskFilter(filter); err != nil {
+		return result, err
+	}
+
+	if _, err := s.checkCategory(ctx, userID, filter.CategoryID); err != nil {
+		return result, err
+	}
+
+	if _, err := s.checkProject(ctx, userID, filter.ProjectID, filter.MilestoneID); err != nil {
+		return result, err
+	}
+
+	filter.UserID = &userID
+	result, err := s.taskRepo.Find(ctx, filter, page)
+	return s.withBlockedPage(ctx, result, err)
 }
 
-func (s *taskService) UpdateTask(task *model.Task) error {
+// FindAssignedTasks returns a page of the tasks the user is assigned to,
+// whoever owns them, matching the filter
+func (s *taskService) FindAssignedTasks(ctx context.Context, userID uuid.UUID, filter model.TaskFilter, page model.PageRequest) (model.Page[model.Task], error) {
+	if err := validateTaskFilter(filter); err != nil {
+		return model.Page[model.Task]{}, err
+	}
+
+	// The category is not checked, as assigned tasks use their owners' categories
+	filter.UserID = nil
+	filter.AssigneeID = &userID
+	result, err := s.taskRepo.Find(ctx, filter, page)
+	return s.withBlockedPage(ctx, result, err)
+}
+
+func (s *taskService) GetTasksByCategory(ctx context.Context, userID, categoryID uuid.UUID, limit, offset int) ([]model.Task, error) {
+	if _, err := s.checkCategory(ctx, userID, &categoryID); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.GetByCategory(ctx, categoryID, limit, offset)
+	return s.withBlocked(ctx, tasks, err)
+}
+
+// UpdateTask saves changes by the owner, a collaborator with edit
+// permission or a workspace owner or admin. The category, project and parent must still belong to the
+// owner.
+func (s *taskService) UpdateTask(ctx context.Context, userID uuid.UUID, task *model.Task) error {
+	existing, err := s.getTask(ctx, userID, task.ID, model.TaskPermissionEdit)
+	if err != nil {
+		return err
+	}
+
+	completing, err := s.checkUpdate(ctx, existing, task)
+	if err != nil {
+		return err
+	}
+
 	task.UpdatedAt = time.Now()
-	return s.taskRepo.Update(task)
+	return s.inTransaction(ctx, func(tx *taskService) error {
+		if err := tx.taskRepo.Update(ctx, task); err != nil {
+			return err
+		}
+
+		if err := tx.recordStatusChange(ctx, userID, task.ID, existing.Status, task.Status, ""); err != nil {
+			return err
+		}
+
+		if completing {
+			return tx.scheduleNextOccurrence(ctx, task)
+		}
+		return nil
+	})
+}
+
+// checkUpdate validates the changes made to a task loaded as existing and
+// reports whether they complete it
+func (s *taskService) checkUpdate(ctx context.Context, existing, task *model.Task) (bool, error) {
+	// Ownership and workspace cannot be changed through an update
+	task.UserID = existing.UserID
+	task.WorkspaceID = existing.WorkspaceID
+
+	if task.Priority == "" {
+		task.Priority = model.TaskPriorityMedium
+	}
+	if err := checkFields(task); err != nil {
+		return false, err
+	}
+
+	category, err := s.checkCategory(ctx, existing.UserID, task.CategoryID)
+	if err != nil {
+		return false, err
+	}
+	// The preloaded category may be stale once the category changes
+	task.Category = category
+
+	projectID, err := s.checkProject(ctx, existing.UserID, task.ProjectID, task.MilestoneID)
+	if err != nil {
+		return false, err
+	}
+	task.ProjectID = projectID
+
+	if !sameID(task.ParentID, existing.ParentID) {
+		if err := s.checkParent(ctx, existing.UserID, task.ID, task.ParentID); err != nil {
+			return false, err
+		}
+	}
+
+	if err := checkRecurrence(task); err != nil {
+		return false, err
+	}
+
+	if err := s.checkTransition(existing.Status, task.Status); err != nil {
+		return false, err
+	}
+
+	if err := checkNotBlocked(existing, task.Status); err != nil {
+		return false, err
+	}
+
+	completing := task.Status == model.TaskStatusCompleted && existing.Status != model.TaskStatusCompleted
+	if completing {
+		if err := s.checkSubtasksClosed(ctx, task.ID); err != nil {
+			return false, err
+		}
+	}
+	return completing, nil
 }
 
-func (s *taskService) UpdateTaskStatus(id uuid.UUID, status model.TaskStatus) error {
-	task, err := s.taskRepo.GetByID(id)
+// UpdateTaskStatus changes the status of a task along the configured
+// transition graph and records the change with the optional reason. A blocked
+// task cannot be started or completed, and completing a task that still has
+// open subtasks is refused unless force is set. Completing an occurrence of a
+// recurring task creates the next occurrence.
+func (s *taskService) UpdateTaskStatus(ctx context.Context, userID, id uuid.UUID, version *int, status model.TaskStatus, reason string, force bool) error {
+	task, err := s.getTask(ctx, userID, id, model.TaskPermissionEdit)
 	if err != nil {
 		return err
 	}
 
+	if err := checkVersion(task.Version, version); err != nil {
+		return err
+	}
+
+	if err := s.checkTransition(task.Status, status); err != nil {
+		return err
+	}
+
+	if err := checkNotBlocked(task, status); err != nil {
+		return err
+	}
+
+	if status == model.TaskStatusCompleted && task.Status != model.TaskStatusCompleted && !force {
+		if err := s.checkSubtasksClosed(ctx, task.ID); err != nil {
+			return err
+		}
+	}
+
+	completing := status == model.TaskStatusCompleted && task.Status != model.TaskStatusCompleted
+
+	from := task.Status
 	task.Status = status
 	task.UpdatedAt = time.Now()
-	return s.taskRepo.Update(task)
+	return s.inTransaction(ctx, func(tx *taskService) error {
+		if err := tx.taskRepo.Update(ctx, task); err != nil {
+			return err
+		}
+
+		if err := tx.recordStatusChange(ctx, userID, task.ID, from, status, reason); err != nil {
+			return err
+		}
+
+		if completing {
+			return tx.scheduleNextOccurrence(ctx, task)
+		}
+		return nil
+	})
+}
+
+// MoveTask places a task in a column of one of the user's boards, right
+// after the task afterID or at the top when afterID is nil. Moving into a
+// column mapped to a status changes the task's status under the same rules
+// as UpdateTaskStatus, without force.
+func (s *taskService) MoveTask(ctx context.Context, userID, id uuid.UUID, version *int, columnID uuid.UUID, afterID *uuid.UUID) (*model.Task, error) {
+	task, err := s.getTask(ctx, userID, id, model.TaskPermissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(task.Version, version); err != nil {
+		return nil, err
+	}
+
+	if afterID != nil && *afterID == id {
+		return nil, fmt.Errorf("%w: a task cannot be moved after itself", ErrInvalidBoard)
+	}
+
+	column, err := s.boardRepo.FindColumn(ctx, columnID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrColumnNotFound)
+	}
+	board, err := s.boardRepo.GetByID(ctx, column.BoardID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrColumnNotFound)
+	}
+	if err := authorizeBoard(userID, board); err != nil {
+		return nil, ErrColumnNotFound
+	}
+
+	from := task.Status
+	var status *model.TaskStatus
+	if column.Status != nil && *column.Status != from {
+		status = column.Status
+		if err := s.checkTransition(from, *status); err != nil {
+			return nil, err
+		}
+		if err := checkNotBlocked(task, *status); err != nil {
+			return nil, err
+		}
+		if *status == model.TaskStatusCompleted {
+			if err := s.checkSubtasksClosed(ctx, task.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The move and its status side effects commit together
+	err = s.inTransaction(ctx, func(tx *taskService) error {
+		rank, err := tx.boardRepo.MoveTask(ctx, id, task.Version, columnID, afterID, status)
+		if err != nil {
+			return err
+		}
+		task.ColumnID = &columnID
+		task.Rank = rank
+		task.Version++
+		task.UpdatedAt = time.Now()
+
+		if status == nil {
+			return nil
+		}
+
+		task.Status = *status
+		if err := tx.recordStatusChange(ctx, userID, task.ID, from, task.Status, ""); err != nil {
+			return err
+		}
+		if task.Status == model.TaskStatusCompleted {
+			return tx.scheduleNextOccurrence(ctx, task)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetStatusHistory returns the status transitions of a task, oldest first
+func (s *taskService) GetStatusHistory(ctx context.Context, userID, id uuid.UUID) ([]model.TaskStatusChange, error) {
+	if _, err := s.GetTaskByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	return s.statusChangeRepo.GetByTaskID(ctx, id)
+}
+
+// DeleteTask deletes a task; only its owner or a workspace owner or admin
+// can
+func (s *taskService) DeleteTask(ctx context.Context, userID, id uuid.UUID, version *int) error {
+	if _, err := s.getTask(ctx, userID, id, model.TaskPermissionOwner); err != nil {
+		return err
+	}
+
+	return s.taskRepo.Delete(ctx, id, version)
+}
+
+func (s *taskService) BulkCreateTasks(ctx context.Context, userID uuid.UUID, tasks []*model.Task, atomic bool) ([]BulkResult, error) {
+	if err := authorizeContribution(s.membership); err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 || len(tasks) > maxBulkTasks {
+		return nil, fmt.Errorf("%w: send 1 to %d tasks", ErrInvalidBulk, maxBulkTasks)
+	}
+
+	results := make([]BulkResult, len(tasks))
+	writes := make([]*repository.BulkTaskWrite, len(tasks))
+	for i, task := range tasks {
+		task.UserID = userID
+		results[i].Task = task
+		if results[i].Err = s.checkNew(ctx, task); results[i].Err == nil {
+			writes[i] = &repository.BulkTaskWrite{Create: task}
+		}
+	}
+
+	if err := s.applyBulk(ctx, results, writes, atomic); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].ID = tasks[i].ID
+		}
+	}
+	return results, nil
 }
 
-func (s *taskService) DeleteTask(id uuid.UUID) error {
-	return s.taskRepo.Delete(id)
+func (s *taskService) BulkUpdateTasks(ctx context.Context, userID uuid.UUID, target BulkTarget, change func(task *model.Task), atomic bool) ([]BulkResult, error) {
+	ids, err := s.bulkTargetIDs(ctx, userID, target)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, len(ids))
+	writes := make([]*repository.BulkTaskWrite, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+		writes[i], results[i].Err = s.prepareBulkUpdate(ctx, userID, id, change)
+		if writes[i] != nil {
+			results[i].Task = writes[i].Update
+		}
+	}
+
+	if err := s.applyBulk(ctx, results, writes, atomic); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func (s *taskService) ListTasks(limit, offset int) ([]model.Task, error) {
-	return s.taskRepo.List(limit, offset)
+// prepareBulkUpdate applies the change to a copy of a task and validates it
+// like UpdateTask, returning the writes that UpdateTask would make
+func (s *taskService) prepareBulkUpdate(ctx context.Context, userID, id uuid.UUID, change func(task *model.Task)) (*repository.BulkTaskWrite, error) {
+	existing, err := s.getTask(ctx, userID, id, model.TaskPermissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	task := *existing
+	change(&task)
+	completing, err := s.checkUpdate(ctx, existing, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	write := &repository.BulkTaskWrite{Update: &task}
+	if task.Status != existing.Status {
+		write.StatusChange = &model.TaskStatusChange{
+			TaskID:     id,
+			UserID:     userID,
+			FromStatus: existing.Status,
+			ToStatus:   task.Status,
+		}
+	}
+	if completing {
+		if write.Successor, err = s.nextOccurrence(ctx, &task); err != nil {
+			return nil, err
+		}
+	}
+	return write, nil
+}
+
+func (s *taskService) BulkDeleteTasks(ctx context.Context, userID uuid.UUID, target BulkTarget, atomic bool) ([]BulkResult, error) {
+	ids, err := s.bulkTargetIDs(ctx, userID, target)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, len(ids))
+	writes := make([]*repository.BulkTaskWrite, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+		if _, results[i].Err = s.getTask(ctx, userID, id, model.TaskPermissionOwner); results[i].Err == nil {
+			writes[i] = &repository.BulkTaskWrite{Delete: &ids[i]}
+		}
+	}
+
+	if err := s.applyBulk(ctx, results, writes, atomic); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// bulkTargetIDs resolves the tasks selected by a bulk request
+func (s *taskService) bulkTargetIDs(ctx context.Context, userID uuid.UUID, target BulkTarget) ([]uuid.UUID, error) {
+	if len(target.IDs) > 0 {
+		var ids []uuid.UUID
+		seen := make(map[uuid.UUID]bool, len(target.IDs))
+		for _, id := range target.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > maxBulkTasks {
+			return nil, fmt.Errorf("%w: at most %d tasks can be changed at once", ErrInvalidBulk, maxBulkTasks)
+		}
+		return ids, nil
+	}
+	if target.Filter == nil {
+		return nil, fmt.Errorf("%w: select the tasks by ids or filter", ErrInvalidBulk)
+	}
+
+	page, err := s.FindTasks(ctx, userID, *target.Filter, model.PageRequest{Limit: maxBulkTasks})
+	if err != nil {
+		return nil, err
+	}
+	if page.HasMore {
+		return nil, fmt.Errorf("%w: the filter matches more than %d tasks", ErrInvalidBulk, maxBulkTasks)
+	}
+
+	ids := make([]uuid.UUID, len(page.Items))
+	for i := range page.Items {
+		ids[i] = page.Items[i].ID
+	}
+	return ids, nil
+}
+
+// applyBulk performs the writes of the items that passed validation and
+// records the outcome in results. In an atomic request nothing is written
+// once an item failed, and the other items are reported as aborted.
+func (s *taskService) applyBulk(ctx context.Context, results []BulkResult, writes []*repository.BulkTaskWrite, atomic bool) error {
+	var pending []repository.BulkTaskWrite
+	var indexes []int
+	for i, write := range writes {
+		if results[i].Err == nil {
+			pending = append(pending, *write)
+			indexes = append(indexes, i)
+		}
+	}
+
+	failed := len(pending) < len(writes)
+	if !(atomic && failed) && len(pending) > 0 {
+		failures, err := s.taskRepo.ApplyBulk(ctx, pending, atomic)
+		for j, i := range indexes {
+			if failures[j] != nil {
+				results[i].Err = notFoundAs(failures[j], ErrTaskNotFound)
+				failed = true
+			}
+		}
+		if err != nil && !failed {
+			return err
+		}
+	}
+
+	if atomic && failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = ErrBulkAborted
+			}
+		}
+	}
+	for i := range results {
+		if results[i].Err != nil {
+			results[i].Task = nil
+		}
+	}
+	return nil
+}
+
+func (s *taskService) GetSubtasks(ctx context.Context, userID, id uuid.UUID) ([]model.Task, error) {
+	if _, err := s.GetTaskByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.GetChildren(ctx, id)
+	return s.withBlocked(ctx, tasks, err)
+}
+
+// GetTaskTree returns the task with all of its descendants nested below it
+func (s *taskService) GetTaskTree(ctx context.Context, userID, id uuid.UUID) (*TaskTree, error) {
+	root, err := s.GetTaskByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := s.taskRepo.GetDescendants(ctx, id)
+	descendants, err = s.withBlocked(ctx, descendants, err)
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[uuid.UUID][]model.Task{}
+	for _, task := range descendants {
+		children[*task.ParentID] = append(children[*task.ParentID], task)
+	}
+
+	tree, _, _ := buildTaskTree(*root, children)
+	return tree, nil
+}
+
+// GetDependencies returns the tasks the given task is blocked by
+func (s *taskService) GetDependencies(ctx context.Context, userID, id uuid.UUID) ([]model.Task, error) {
+	if _, err := s.GetTaskByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.dependencyRepo.GetDependencies(ctx, id)
+	return s.withBlocked(ctx, tasks, err)
+}
+
+// AddDependency marks the task as blocked by dependsOnID. The user needs edit
+// permission on the task, both tasks must have the same owner, and the new
+// dependency must not close a cycle.
+func (s *taskService) AddDependency(ctx context.Context, userID, id, dependsOnID uuid.UUID) error {
+	task, err := s.getTask(ctx, userID, id, model.TaskPermissionEdit)
+	if err != nil {
+		return err
+	}
+	blocker, err := s.GetTaskByID(ctx, userID, dependsOnID)
+	if err != nil {
+		return err
+	}
+	if blocker.UserID != task.UserID {
+		return ErrTaskNotFound
+	}
+
+	if id == dependsOnID {
+		return ErrDependencyCycle
+	}
+
+	exists, err := s.dependencyRepo.Exists(ctx, id, dependsOnID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrDependencyExists
+	}
+
+	// A cycle is closed when the task is already upstream of its new blocker
+	upstream, err := s.dependencyRepo.GetUpstreamIDs(ctx, dependsOnID)
+	if err != nil {
+		return err
+	}
+	for _, upstreamID := range upstream {
+		if upstreamID == id {
+			return ErrDependencyCycle
+		}
+	}
+
+	return s.dependencyRepo.Create(ctx, &model.TaskDependency{TaskID: id, DependsOnID: dependsOnID})
+}
+
+func (s *taskService) RemoveDependency(ctx context.Context, userID, id, dependsOnID uuid.UUID) error {
+	if _, err := s.getTask(ctx, userID, id, model.TaskPermissionEdit); err != nil {
+		return err
+	}
+
+	removed, err := s.dependencyRepo.Delete(ctx, id, dependsOnID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrDependencyNotFound
+	}
+	return nil
+}
+
+// ListTasks returns a page of the tasks of all users, or of all users of the
+// workspace when the service is limited to one
+func (s *taskService) ListTasks(ctx context.Context, page model.PageRequest) (model.Page[model.Task], error) {
+	result, err := s.taskRepo.Find(ctx, model.TaskFilter{}, page)
+	return s.withBlockedPage(ctx, result, err)
+}
+
+// validateTaskFilter checks the values of a filter supplied by a client
+func validateTaskFilter(filter model.TaskFilter) error {
+	for _, status := range filter.Statuses {
+		if !status.IsValid() {
+			return fmt.Errorf("%w %q", ErrInvalidStatus, status)
+		}
+	}
+	for _, priority := range filter.Priorities {
+		if !priority.IsValid() {
+			return fmt.Errorf("%w: unknown priority %q", ErrInvalidFilter, priority)
+		}
+	}
+	if filter.SortBy != "" && !filter.SortBy.IsValid() {
+		return fmt.Errorf("%w: cannot sort by %q", ErrInvalidFilter, filter.SortBy)
+	}
+	return nil
+}
+
+// checkCategory ensures a task only references a category of its own user
+// and returns that category, if any
+func (s *taskService) checkCategory(ctx context.Context, userID uuid.UUID, categoryID *uuid.UUID) (*model.Category, error) {
+	if categoryID == nil {
+		return nil, nil
+	}
+
+	category, err := s.categoryRepo.GetByID(ctx, *categoryID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrCategoryNotFound)
+	}
+
+	if err := authorizeCategory(userID, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// checkProject ensures a task only references a project of its own user and
+// a milestone of that project. It returns the task's project, which a
+// milestone alone implies.
+func (s *taskService) checkProject(ctx context.Context, userID uuid.UUID, projectID, milestoneID *uuid.UUID) (*uuid.UUID, error) {
+	if milestoneID != nil {
+		if projectID == nil {
+			milestone, err := s.projectRepo.FindMilestone(ctx, *milestoneID)
+			if err != nil {
+				return nil, notFoundAs(err, ErrMilestoneNotFound)
+			}
+			projectID = &milestone.ProjectID
+		} else if _, err := s.projectRepo.GetMilestone(ctx, *projectID, *milestoneID); err != nil {
+			return nil, notFoundAs(err, ErrMilestoneNotFound)
+		}
+	}
+
+	if projectID == nil {
+		return nil, nil
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, *projectID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrProjectNotFound)
+	}
+
+	if err := authorizeProject(userID, project); err != nil {
+		return nil, err
+	}
+	return projectID, nil
+}
+
+// checkParent ensures a task is only nested under a task of its own user and
+// never under itself or one of its own subtasks
+func (s *taskService) checkParent(ctx context.Context, userID, taskID uuid.UUID, parentID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+
+	parent, err := s.taskRepo.GetByID(ctx, *parentID)
+	if err != nil {
+		return notFoundAs(err, ErrParentTaskNotFound)
+	}
+	if authorizeTask(userID, parent) != nil {
+		return ErrParentTaskNotFound
+	}
+
+	if taskID == uuid.Nil {
+		return nil
+	}
+	if *parentID == taskID {
+		return ErrTaskCycle
+	}
+
+	ancestors, err := s.taskRepo.GetAncestorIDs(ctx, *parentID)
+	if err != nil {
+		return err
+	}
+	for _, ancestorID := range ancestors {
+		if ancestorID == taskID {
+			return ErrTaskCycle
+		}
+	}
+
+	return nil
+}
+
+// checkSubtasksClosed refuses when any descendant is still pending or in progress
+func (s *taskService) checkSubtasksClosed(ctx context.Context, id uuid.UUID) error {
+	descendants, err := s.taskRepo.GetDescendants(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range descendants {
+		if isOpen(task.Status) {
+			return ErrOpenSubtasks
+		}
+	}
+	return nil
+}
+
+// buildTaskTree nests the children below the task and computes progress. It
+// also returns the number of completed and counted descendants; cancelled
+// tasks are not counted.
+func buildTaskTree(task model.Task, children map[uuid.UUID][]model.Task) (*TaskTree, int, int) {
+	node := &TaskTree{Task: task, Children: []*TaskTree{}}
+	completed, counted := 0, 0
+
+	for _, child := range children[task.ID] {
+		childNode, childCompleted, childCounted := buildTaskTree(child, children)
+		node.Children = append(node.Children, childNode)
+
+		completed += childCompleted
+		counted += childCounted
+		if child.Status != model.TaskStatusCancelled {
+			counted++
+			if child.Status == model.TaskStatusCompleted {
+				completed++
+			}
+		}
+	}
+
+	switch {
+	case counted > 0:
+		node.Progress = float64(completed) * 100 / float64(counted)
+	case task.Status == model.TaskStatusCompleted:
+		node.Progress = 100
+	}
+
+	return node, completed, counted
+}
+
+// scheduleNextOccurrence creates the occurrence that follows a completed
+// recurring task, unless the series is exhausted or it was created before
+func (s *taskService) scheduleNextOccurrence(ctx context.Context, task *model.Task) error {
+	next, err := s.nextOccurrence(ctx, task)
+	if err != nil || next == nil {
+		return err
+	}
+
+	if err := s.taskRepo.Create(ctx, next); err != nil {
+		return err
+	}
+	return s.collaboratorRepo.CopyCollaborators(ctx, task.ID, next.ID)
+}
+
+// nextOccurrence builds the occurrence that follows a completed recurring
+// task. It returns nil when the series is exhausted or the occurrence exists.
+func (s *taskService) nextOccurrence(ctx context.Context, task *model.Task) (*model.Task, error) {
+	if task.Recurrence == "" || task.DueDate == nil {
+		return nil, nil
+	}
+
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence := task.Occurrence
+	if occurrence < 1 {
+		occurrence = 1
+	}
+	dueDate, ok := rule.Next(*task.DueDate, occurrence)
+	if !ok {
+		return nil, nil
+	}
+
+	// The first occurrence is the head of its own series
+	seriesID := task.ID
+	if task.SeriesID != nil {
+		seriesID = *task.SeriesID
+	}
+
+	// Reopening and completing an occurrence again must not duplicate its successor
+	exists, err := s.taskRepo.HasOccurrence(ctx, seriesID, occurrence+1)
+	if err != nil || exists {
+		return nil, err
+	}
+
+	return &model.Task{
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      model.TaskStatusPending,
+		Priority:    task.Priority,
+		DueDate:     &dueDate,
+		UserID:      task.UserID,
+		WorkspaceID: task.WorkspaceID,
+		CategoryID:  task.CategoryID,
+		ProjectID:   task.ProjectID,
+		MilestoneID: task.MilestoneID,
+		ParentID:    task.ParentID,
+		Recurrence:  task.Recurrence,
+		SeriesID:    &seriesID,
+		Occurrence:  occurrence + 1,
+		Tags:        task.Tags,
+	}, nil
+}
+
+// markBlocked sets the computed Blocked flag on the tasks
+func (s *taskService) markBlocked(ctx context.Context, tasks []model.Task) error {
+	ids := make([]uuid.UUID, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+	}
+
+	blockedIDs, err := s.dependencyRepo.GetBlockedIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	blocked := make(map[uuid.UUID]bool, len(blockedIDs))
+	for _, id := range blockedIDs {
+		blocked[id] = true
+	}
+	for i := range tasks {
+		tasks[i].Blocked = blocked[tasks[i].ID]
+	}
+	return nil
+}
+
+// withBlocked wraps a repository result, setting Blocked on the tasks
+func (s *taskService) withBlocked(ctx context.Context, tasks []model.Task, err error) ([]model.Task, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markBlocked(ctx, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// checkTransition validates the new status and that the transition graph
+// allows moving to it. Keeping the current status is always allowed.
+func (s *taskService) checkTransition(from, to model.TaskStatus) error {
+	if !to.IsValid() {
+		return fmt.Errorf("%w %q", ErrInvalidStatus, to)
+	}
+	if from == to {
+		return nil
+	}
+
+	for _, allowed := range s.transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+}
+
+// recordStatusChange adds a history row when the status actually changed
+func (s *taskService) recordStatusChange(ctx context.Context, userID, taskID uuid.UUID, from, to model.TaskStatus, reason string) error {
+	if from == to {
+		return nil
+	}
+
+	return s.statusChangeRepo.Create(ctx, &model.TaskStatusChange{
+		TaskID:     taskID,
+		UserID:     userID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+	})
+}
+
+// checkFields validates the plain fields of a task that is created or updated
+func checkFields(task *model.Task) error {
+	if task.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidTask)
+	}
+	if task.Priority != "" && !task.Priority.IsValid() {
+		return fmt.Errorf("%w: unknown priority %q", ErrInvalidTask, task.Priority)
+	}
+	return nil
+}
+
+// checkRecurrence validates the recurrence rule of a task, which needs a due
+// date to schedule occurrences from
+func checkRecurrence(task *model.Task) error {
+	if task.Recurrence == "" {
+		return nil
+	}
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	if task.DueDate == nil {
+		return fmt.Errorf("%w: a recurring task requires a due date", ErrInvalidRecurrence)
+	}
+	if !rule.Repeats(*task.DueDate) {
+		return fmt.Errorf("%w: the rule never repeats after the due date", ErrInvalidRecurrence)
+	}
+	return nil
+}
+
+// withBlockedPage is withBlocked for a page of tasks
+func (s *taskService) withBlockedPage(ctx context.Context, page model.Page[model.Task], err error) (model.Page[model.Task], error) {
+	if err != nil {
+		return page, err
+	}
+	if err := s.markBlocked(ctx, page.Items); err != nil {
+		return page, err
+	}
+	return page, nil
+}
+
+// checkNotBlocked refuses starting or completing a blocked task
+func checkNotBlocked(task *model.Task, status model.TaskStatus) error {
+	if !task.Blocked || status == task.Status {
+		return nil
+	}
+	if status == model.TaskStatusInProgress || status == model.TaskStatusCompleted {
+		return ErrTaskBlocked
+	}
+	return nil
+}
+
+func isOpen(status model.TaskStatus) bool {
+	return status != model.TaskStatusCompleted && status != model.TaskStatusCancelled
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
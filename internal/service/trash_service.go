@@ -0,0 +1,204 @@
+package service
+
+import (
+	"Arise-test/configs"
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"Arise-test/internal/storage"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrashedTask is a deleted task with when it was deleted and, when the trash
+// has a retention period, when it will be purged
+type TrashedTask struct {
+	model.Task
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`
+}
+
+// TrashedCategory is a deleted category, see TrashedTask
+type TrashedCategory struct {
+	model.Category
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`
+}
+
+// Trash lists a user's deleted tasks and categories
+type Trash struct {
+	Tasks      []TrashedTask     `json:"tasks"`
+	Categories []TrashedCategory `json:"categories"`
+}
+
+type TrashService interface {
+	GetTrash(ctx context.Context, userID uuid.UUID) (*Trash, error)
+	RestoreTask(ctx context.Context, userID, id uuid.UUID) error
+	RestoreCategory(ctx context.Context, userID, id uuid.UUID) error
+	PurgeTask(ctx context.Context, userID, id uuid.UUID) error
+	PurgeCategory(ctx context.Context, userID, id uuid.UUID) error
+	EmptyTrash(ctx context.Context, userID uuid.UUID) error
+	// PurgeExpired purges the items of every workspace that have been in the
+	// trash longer than the retention period and reports how many there were
+	PurgeExpired(ctx context.Context) (int64, error)
+	// InWorkspace returns the service limited to the trash of the
+	// membership's workspace
+	InWorkspace(membership model.Membership) TrashService
+}
+
+type trashService struct {
+	trashRepo  repository.TrashRepository
+	storage    storage.Storage
+	retention  time.Duration
+	membership *model.Membership
+}
+
+func NewTrashService(trashRepo repository.TrashRepository, fileStorage storage.Storage, config configs.TrashConfig) TrashService {
+	return &trashService{
+		trashRepo: trashRepo,
+		storage:   fileStorage,
+		retention: config.Retention,
+	}
+}
+
+func (s *trashService) InWorkspace(membership model.Membership) TrashService {
+	scoped := *s
+	scoped.trashRepo = s.trashRepo.InWorkspace(membership.WorkspaceID)
+	scoped.membership = &membership
+	return &scoped
+}
+
+func (s *trashService) GetTrash(ctx context.Context, userID uuid.UUID) (*Trash, error) {
+	tasks, err := s.trashRepo.GetTasks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.trashRepo.GetCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trash := &Trash{Tasks: []TrashedTask{}, Categories: []TrashedCategory{}}
+	for _, task := range tasks {
+		deletedAt := task.DeletedAt.Time
+		trash.Tasks = append(trash.Tasks, TrashedTask{Task: task, DeletedAt: deletedAt, PurgeAt: s.purgeAt(deletedAt)})
+	}
+	for _, category := range categories {
+		deletedAt := category.DeletedAt.Time
+		trash.Categories = append(trash.Categories, TrashedCategory{Category: category, DeletedAt: deletedAt, PurgeAt: s.purgeAt(deletedAt)})
+	}
+	return trash, nil
+}
+
+// RestoreTask brings back one of the user's deleted tasks, and its category
+// if that was deleted too
+func (s *trashService) RestoreTask(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.getTask(ctx, userID, id); err != nil {
+		return err
+	}
+	return notFoundAs(s.trashRepo.RestoreTask(ctx, id), ErrTaskNotFound)
+}
+
+func (s *trashService) RestoreCategory(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.getCategory(ctx, userID, id); err != nil {
+		return err
+	}
+	return notFoundAs(s.trashRepo.RestoreCategory(ctx, id), ErrCategoryNotFound)
+}
+
+// PurgeTask deletes one of the user's deleted tasks for good, with its
+// attachment files
+func (s *trashService) PurgeTask(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.getTask(ctx, userID, id); err != nil {
+		return err
+	}
+
+	keys, err := s.trashRepo.PurgeTask(ctx, id)
+	if err != nil {
+		return notFoundAs(err, ErrTaskNotFound)
+	}
+	return s.deleteFiles(ctx, keys)
+}
+
+func (s *trashService) PurgeCategory(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.getCategory(ctx, userID, id); err != nil {
+		return err
+	}
+	return notFoundAs(s.trashRepo.PurgeCategory(ctx, id), ErrCategoryNotFound)
+}
+
+// EmptyTrash purges all of the user's deleted tasks and categories
+func (s *trashService) EmptyTrash(ctx context.Context, userID uuid.UUID) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	keys, err := s.trashRepo.PurgeUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.deleteFiles(ctx, keys)
+}
+
+func (s *trashService) PurgeExpired(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	purged, keys, err := s.trashRepo.PurgeDeletedBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+	return purged, s.deleteFiles(ctx, keys)
+}
+
+// getTask checks that the deleted task belongs to the user and that they may
+// change the workspace
+func (s *trashService) getTask(ctx context.Context, userID, id uuid.UUID) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	task, err := s.trashRepo.GetTask(ctx, id)
+	if err != nil {
+		return notFoundAs(err, ErrTaskNotFound)
+	}
+	return authorizeTask(userID, task)
+}
+
+// getCategory checks that the deleted category belongs to the user and that
+// they may change the workspace
+func (s *trashService) getCategory(ctx context.Context, userID, id uuid.UUID) error {
+	if err := authorizeContribution(s.membership); err != nil {
+		return err
+	}
+
+	category, err := s.trashRepo.GetCategory(ctx, id)
+	if err != nil {
+		return notFoundAs(err, ErrCategoryNotFound)
+	}
+	return authorizeCategory(userID, category)
+}
+
+// deleteFiles removes the files of purged attachments. The rows are already
+// gone, so every file is tried and the errors are joined.
+func (s *trashService) deleteFiles(ctx context.Context, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// purgeAt returns when an item deleted at the time will be purged
+func (s *trashService) purgeAt(deletedAt time.Time) *time.Time {
+	if s.retention <= 0 {
+		return nil
+	}
+	purgeAt := deletedAt.Add(s.retention)
+	return &purgeAt
+}
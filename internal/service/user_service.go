@@ -3,41 +3,61 @@ package service
 import (
 	"Arise-test/internal/model"
 	"Arise-test/internal/repository"
+	"context"
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrUserHasContent     = errors.New("user still owns tasks or categories")
+	ErrSoleWorkspaceOwner = errors.New("user is the only owner of a team workspace")
+)
+
 type UserService interface {
-	CreateUser(user *model.User) error
-	GetUserByID(id uuid.UUID) (*model.User, error)
-	GetUserByEmail(email string) (*model.User, error)
-	GetUserByUsername(username string) (*model.User, error)
-	UpdateUser(user *model.User) error
-	DeleteUser(id uuid.UUID) error
-	ListUsers(limit, offset int) ([]model.User, error)
+	CreateUser(ctx context.Context, user *model.User) error
+	GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+	// UpdateUser and DeleteUser only apply to the user at the given version;
+	// a nil version applies to any
+	UpdateUser(ctx context.Context, actorID uuid.UUID, user *model.User, version *int) error
+	DeleteUser(ctx context.Context, actorID, id uuid.UUID, version *int, policy model.UserDeletePolicy) error
+	ListUsers(ctx context.Context, page model.PageRequest) (model.Page[model.User], error)
 	ValidatePassword(hashedPassword, password string) bool
 	HashPassword(password string) (string, error)
+	// InTransaction returns the service bound to the repositories of a unit
+	// of work
+	InTransaction(repos repository.Repositories) UserService
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo  repository.UserRepository
+	txManager repository.TxManager
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(userRepo repository.UserRepository, txManager repository.TxManager) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:  userRepo,
+		txManager: txManager,
 	}
 }
 
-func (s *userService) CreateUser(user *model.User) error {
+func (s *userService) InTransaction(repos repository.Repositories) UserService {
+	scoped := *s
+	scoped.userRepo = repos.Users
+	return &scoped
+}
+
+func (s *userService) CreateUser(ctx context.Context, user *model.User) error {
 	// Check if user already exists
-	if _, err := s.userRepo.GetByEmail(user.Email); err == nil {
+	if _, err := s.userRepo.GetByEmail(ctx, user.Email); err == nil {
 		return errors.New("user with this email already exists")
 	}
 
-	if _, err := s.userRepo.GetByUsername(user.Username); err == nil {
+	if _, err := s.userRepo.GetByUsername(ctx, user.Username); err == nil {
 		return errors.New("user with this username already exists")
 	}
 
@@ -48,31 +68,106 @@ func (s *userService) CreateUser(user *model.User) error {
 	}
 	user.Password = hashedPassword
 
-	return s.userRepo.Create(user)
+	// New accounts always start with the default role
+	user.Role = model.RoleUser
+
+	return s.userRepo.Create(ctx, user)
 }
 
-func (s *userService) GetUserByID(id uuid.UUID) (*model.User, error) {
-	return s.userRepo.GetByID(id)
+func (s *userService) GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	return s.userRepo.GetByID(ctx, id)
 }
 
-func (s *userService) GetUserByEmail(email string) (*model.User, error) {
-	return s.userRepo.GetByEmail(email)
+func (s *userService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return s.userRepo.GetByEmail(ctx, email)
 }
 
-func (s *userService) GetUserByUsername(username string) (*model.User, error) {
-	return s.userRepo.GetByUsername(username)
+func (s *userService) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	return s.userRepo.GetByUsername(ctx, username)
 }
 
-func (s *userService) UpdateUser(user *model.User) error {
-	return s.userRepo.Update(user)
+func (s *userService) UpdateUser(ctx context.Context, actorID uuid.UUID, user *model.User, version *int) error {
+	if err := authorizeUser(actorID, user.ID); err != nil {
+		return err
+	}
+
+	if err := checkVersion(user.Version, version); err != nil {
+		return err
+	}
+
+	return s.userRepo.Update(ctx, user)
 }
 
-func (s *userService) DeleteUser(id uuid.UUID) error {
-	return s.userRepo.Delete(id)
+// DeleteUser deletes the actor's own account together with their personal
+// workspace, memberships and pending invitations. It is refused while the
+// user is the only owner of a team workspace. With the default restrict
+// policy it is also refused while the user still has tasks or categories;
+// the cascade policy deletes them along with the user.
+func (s *userService) DeleteUser(ctx context.Context, actorID, id uuid.UUID, version *int, policy model.UserDeletePolicy) error {
+	if err := authorizeUser(actorID, id); err != nil {
+		return err
+	}
+
+	if policy == "" {
+		policy = model.UserDeleteRestrict
+	}
+	if !policy.IsValid() {
+		return fmt.Errorf("%w %q", ErrInvalidDeletePolicy, policy)
+	}
+
+	return s.txManager.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		user, err := repos.Users.GetByID(ctx, id)
+		if err != nil {
+			return notFoundAs(err, ErrUserNotFound)
+		}
+
+		// Team workspaces must not be left without an owner
+		soleOwned, err := repos.Workspaces.CountSoleOwned(ctx, id)
+		if err != nil {
+			return err
+		}
+		if soleOwned > 0 {
+			return ErrSoleWorkspaceOwner
+		}
+
+		if policy == model.UserDeleteRestrict {
+			owned, err := repos.Users.CountOwned(ctx, id)
+			if err != nil {
+				return err
+			}
+			if owned > 0 {
+				return ErrUserHasContent
+			}
+		} else {
+			if err := repos.Tasks.DeleteByUserID(ctx, id); err != nil {
+				return err
+			}
+			if err := repos.Categories.DeleteByUserID(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		// The personal workspace has the user's ID
+		if err := repos.Workspaces.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := repos.Workspaces.DeleteMemberships(ctx, id); err != nil {
+			return err
+		}
+		if err := repos.Invitations.DeletePendingByUser(ctx, id, user.Email); err != nil {
+			return err
+		}
+
+		// Sessions end with the account
+		if err := repos.RefreshTokens.RevokeByUserID(ctx, id); err != nil {
+			return err
+		}
+		return repos.Users.Delete(ctx, id, version)
+	})
 }
 
-func (s *userService) ListUsers(limit, offset int) ([]model.User, error) {
-	return s.userRepo.List(limit, offset)
+func (s *userService) ListUsers(ctx context.Context, page model.PageRequest) (model.Page[model.User], error) {
+	return s.userRepo.List(ctx, page)
 }
 
 func (s *userService) HashPassword(password string) (string, error) {
@@ -0,0 +1,13 @@
+package service
+
+import "Arise-test/internal/repository"
+
+// checkVersion fails with repository.ErrVersionConflict when the caller
+// expects another version of a resource than the one loaded. A nil version
+// expects any.
+func checkVersion(loaded int, version *int) error {
+	if version != nil && *version != loaded {
+		return repository.ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"Arise-test/configs"
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+	ErrMemberNotFound     = errors.New("member not found")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrWorkspaceForbidden = errors.New("insufficient role in workspace")
+	ErrLastOwner          = errors.New("cannot remove the last owner of the workspace")
+	ErrPersonalWorkspace  = errors.New("personal workspaces cannot be deleted")
+	ErrInvitationExpired  = errors.New("invitation has expired or was already used")
+	ErrInvalidWorkspace   = errors.New("invalid workspace")
+	ErrInvalidInvitation  = errors.New("invalid invitation")
+)
+
+// maxWorkspaceNameLength bounds the name of a workspace, in characters
+const maxWorkspaceNameLength = 100
+
+// WorkspaceService manages workspaces, their members and invitations. Only
+// members see a workspace; owners and admins manage it, and only owners
+// grant or take away ownership.
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, userID uuid.UUID, workspace *model.Workspace) error
+	GetWorkspaces(ctx context.Context, userID uuid.UUID) ([]model.Workspace, error)
+	GetWorkspace(ctx context.Context, userID, id uuid.UUID) (*model.Workspace, error)
+	GetMembership(ctx context.Context, userID, workspaceID uuid.UUID) (*model.Membership, error)
+	UpdateWorkspace(ctx context.Context, userID uuid.UUID, workspace *model.Workspace) error
+	DeleteWorkspace(ctx context.Context, userID, id uuid.UUID) error
+	GetMembers(ctx context.Context, userID, workspaceID uuid.UUID) ([]model.Membership, error)
+	UpdateMemberRole(ctx context.Context, userID, workspaceID, memberID uuid.UUID, role model.WorkspaceRole) (*model.Membership, error)
+	RemoveMember(ctx context.Context, userID, workspaceID, memberID uuid.UUID) error
+	// CreateInvitation stores the invitation and returns its token, which
+	// cannot be recovered later
+	CreateInvitation(ctx context.Context, userID uuid.UUID, invitation *model.Invitation) (string, error)
+	GetInvitations(ctx context.Context, userID, workspaceID uuid.UUID) ([]model.Invitation, error)
+	RevokeInvitation(ctx context.Context, userID, workspaceID, id uuid.UUID) error
+	AcceptInvitation(ctx context.Context, userID uuid.UUID, token string) (*model.Membership, error)
+	RegisterWithInvitation(ctx context.Context, token string, user *model.User) (*model.Membership, error)
+}
+
+type workspaceService struct {
+	workspaceRepo  repository.WorkspaceRepository
+	invitationRepo repository.InvitationRepository
+	userRepo       repository.UserRepository
+	userService    UserService
+	txManager      repository.TxManager
+	invitationTTL  time.Duration
+}
+
+func NewWorkspaceService(workspaceRepo repository.WorkspaceRepository, invitationRepo repository.InvitationRepository, userRepo repository.UserRepository, userService UserService, txManager repository.TxManager, config configs.WorkspaceConfig) WorkspaceService {
+	return &workspaceService{
+		workspaceRepo:  workspaceRepo,
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		userService:    userService,
+		txManager:      txManager,
+		invitationTTL:  config.InvitationTTL,
+	}
+}
+
+// CreateWorkspace creates a team workspace owned by the user
+func (s *workspaceService) CreateWorkspace(ctx context.Context, userID uuid.UUID, workspace *model.Workspace) error {
+	name, err := validateWorkspaceName(workspace.Name)
+	if err != nil {
+		return err
+	}
+	workspace.Name = name
+	workspace.Personal = false
+
+	if err := s.workspaceRepo.Create(ctx, workspace, userID); err != nil {
+		return err
+	}
+	workspace.Role = model.WorkspaceRoleOwner
+	return nil
+}
+
+// GetWorkspaces returns the workspaces the user is a member of
+func (s *workspaceService) GetWorkspaces(ctx context.Context, userID uuid.UUID) ([]model.Workspace, error) {
+	return s.workspaceRepo.GetByUserID(ctx, userID)
+}
+
+func (s *workspaceService) GetWorkspace(ctx context.Context, userID, id uuid.UUID) (*model.Workspace, error) {
+	membership, err := s.GetMembership(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrWorkspaceNotFound)
+	}
+	workspace.Role = membership.Role
+	return workspace, nil
+}
+
+// GetMembership returns the user's membership of a workspace. Workspaces the
+// user is not a member of are reported as not found.
+func (s *workspaceService) GetMembership(ctx context.Context, userID, workspaceID uuid.UUID) (*model.Membership, error) {
+	membership, err := s.workspaceRepo.GetMembership(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrWorkspaceNotFound)
+	}
+	return membership, nil
+}
+
+// UpdateWorkspace renames a workspace; owners and admins can
+func (s *workspaceService) UpdateWorkspace(ctx context.Context, userID uuid.UUID, workspace *model.Workspace) error {
+	membership, err := s.authorize(ctx, userID, workspace.ID, model.WorkspaceRole.CanManage)
+	if err != nil {
+		return err
+	}
+
+	name, err := validateWorkspaceName(workspace.Name)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.workspaceRepo.GetByID(ctx, workspace.ID)
+	if err != nil {
+		return notFoundAs(err, ErrWorkspaceNotFound)
+	}
+	existing.Name = name
+	existing.UpdatedAt = time.Now()
+	if err := s.workspaceRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+
+	*workspace = *existing
+	workspace.Role = membership.Role
+	return nil
+}
+
+// DeleteWorkspace deletes a team workspace with its tasks and categories;
+// only owners can
+func (s *workspaceService) DeleteWorkspace(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.authorize(ctx, userID, id, isOwner); err != nil {
+		return err
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, id)
+	if err != nil {
+		return notFoundAs(err, ErrWorkspaceNotFound)
+	}
+	if workspace.Personal {
+		return ErrPersonalWorkspace
+	}
+
+	return s.workspaceRepo.Delete(ctx, id)
+}
+
+func (s *workspaceService) GetMembers(ctx context.Context, userID, workspaceID uuid.UUID) ([]model.Membership, error) {
+	if _, err := s.GetMembership(ctx, userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	return s.workspaceRepo.GetMembers(ctx, workspaceID)
+}
+
+// UpdateMemberRole changes the role of a member. Owners and admins can, but
+// only owners can make or unmake an owner, and the last owner stays one.
+func (s *workspaceService) UpdateMemberRole(ctx context.Context, userID, workspaceID, memberID uuid.UUID, role model.WorkspaceRole) (*model.Membership, error) {
+	actor, err := s.authorize(ctx, userID, workspaceID, model.WorkspaceRole.CanManage)
+	if err != nil {
+		return nil, err
+	}
+
+	if !role.IsValid() {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidWorkspace, role)
+	}
+
+	member, err := s.getMember(ctx, workspaceID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == model.WorkspaceRoleOwner || member.Role == model.WorkspaceRoleOwner {
+		if actor.Role != model.WorkspaceRoleOwner {
+			return nil, ErrWorkspaceForbidden
+		}
+	}
+	if role == model.WorkspaceRoleOwner && workspaceID != memberID {
+		workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+		if err != nil {
+			return nil, notFoundAs(err, ErrWorkspaceNotFound)
+		}
+		if workspace.Personal {
+			return nil, fmt.Errorf("%w: a personal workspace has a single owner", ErrInvalidWorkspace)
+		}
+	}
+	if member.Role == model.WorkspaceRoleOwner && role != model.WorkspaceRoleOwner {
+		if err := s.checkNotLastOwner(ctx, workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = role
+	if err := s.workspaceRepo.SaveMembership(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember takes a user out of a workspace. Members can leave on their
+// own; owners and admins remove others, except that only owners remove an
+// owner. The last owner cannot leave.
+func (s *workspaceService) RemoveMember(ctx context.Context, userID, workspaceID, memberID uuid.UUID) error {
+	actor, err := s.GetMembership(ctx, userID, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.getMember(ctx, workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if userID != memberID {
+		if !actor.Role.CanManage() {
+			return ErrWorkspaceForbidden
+		}
+		if member.Role == model.WorkspaceRoleOwner && actor.Role != model.WorkspaceRoleOwner {
+			return ErrWorkspaceForbidden
+		}
+	}
+	if member.Role == model.WorkspaceRoleOwner {
+		if err := s.checkNotLastOwner(ctx, workspaceID); err != nil {
+			return err
+		}
+	}
+
+	removed, err := s.workspaceRepo.DeleteMembership(ctx, workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// CreateInvitation invites an email address to the workspace with a role
+// other than owner; owners and admins can
+func (s *workspaceService) CreateInvitation(ctx context.Context, userID uuid.UUID, invitation *model.Invitation) (string, error) {
+	if _, err := s.authorize(ctx, userID, invitation.WorkspaceID, model.WorkspaceRole.CanManage); err != nil {
+		return "", err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(invitation.Email))
+	if !strings.Contains(email, "@") {
+		return "", fmt.Errorf("%w: email is required", ErrInvalidInvitation)
+	}
+	if !invitation.Role.IsValid() || invitation.Role == model.WorkspaceRoleOwner {
+		return "", fmt.Errorf("%w: role must be admin, member or guest", ErrInvalidInvitation)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	invitation.Email = email
+	invitation.TokenHash = hashToken(token)
+	invitation.InvitedByID = userID
+	invitation.ExpiresAt = time.Now().Add(s.invitationTTL)
+	invitation.AcceptedAt = nil
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GetInvitations returns the invitations of a workspace that can still be
+// accepted
+func (s *workspaceService) GetInvitations(ctx context.Context, userID, workspaceID uuid.UUID) ([]model.Invitation, error) {
+	if _, err := s.authorize(ctx, userID, workspaceID, model.WorkspaceRole.CanManage); err != nil {
+		return nil, err
+	}
+
+	return s.invitationRepo.GetPending(ctx, workspaceID)
+}
+
+func (s *workspaceService) RevokeInvitation(ctx context.Context, userID, workspaceID, id uuid.UUID) error {
+	if _, err := s.authorize(ctx, userID, workspaceID, model.WorkspaceRole.CanManage); err != nil {
+		return err
+	}
+
+	removed, err := s.invitationRepo.Delete(ctx, workspaceID, id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation adds an existing user to the workspace they were invited
+// to. The invitation must have been sent to the user's email address.
+func (s *workspaceService) AcceptInvitation(ctx context.Context, userID uuid.UUID, token string) (*model.Membership, error) {
+	invitation, err := s.getInvitation(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationNotFound
+	}
+
+	return s.accept(ctx, invitation, userID)
+}
+
+// RegisterWithInvitation creates an account for the invited email address
+// and adds it to the workspace. The invitation row is locked first and the
+// account is only kept when the invitation is accepted with it.
+func (s *workspaceService) RegisterWithInvitation(ctx context.Context, token string, user *model.User) (*model.Membership, error) {
+	var invitation *model.Invitation
+	err := s.txManager.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		var err error
+		invitation, err = usableInvitation(repos.Invitations.LockByTokenHash(ctx, hashToken(token)))
+		if err != nil {
+			return err
+		}
+
+		user.Email = invitation.Email
+		if err := s.userService.InTransaction(repos).CreateUser(ctx, user); err != nil {
+			return err
+		}
+
+		return acceptInvitation(ctx, repos.Invitations, invitation, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetMembership(ctx, user.ID, invitation.WorkspaceID)
+}
+
+// getInvitation looks up an invitation by its token and checks that it can
+// still be accepted
+func (s *workspaceService) getInvitation(ctx context.Context, token string) (*model.Invitation, error) {
+	return usableInvitation(s.invitationRepo.GetByTokenHash(ctx, hashToken(token)))
+}
+
+// usableInvitation checks that a looked up invitation can still be accepted
+func usableInvitation(invitation *model.Invitation, err error) (*model.Invitation, error) {
+	if err != nil {
+		return nil, notFoundAs(err, ErrInvitationNotFound)
+	}
+	if invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	return invitation, nil
+}
+
+func (s *workspaceService) accept(ctx context.Context, invitation *model.Invitation, userID uuid.UUID) (*model.Membership, error) {
+	if err := acceptInvitation(ctx, s.invitationRepo, invitation, userID); err != nil {
+		return nil, err
+	}
+	return s.GetMembership(ctx, userID, invitation.WorkspaceID)
+}
+
+func acceptInvitation(ctx context.Context, invitations repository.InvitationRepository, invitation *model.Invitation, userID uuid.UUID) error {
+	if err := invitations.Accept(ctx, invitation, userID); err != nil {
+		return notFoundAs(err, ErrInvitationExpired)
+	}
+	return nil
+}
+
+// authorize returns the user's membership when its role passes the check
+func (s *workspaceService) authorize(ctx context.Context, userID, workspaceID uuid.UUID, allowed func(model.WorkspaceRole) bool) (*model.Membership, error) {
+	membership, err := s.GetMembership(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed(membership.Role) {
+		return nil, ErrWorkspaceForbidden
+	}
+	return membership, nil
+}
+
+func (s *workspaceService) getMember(ctx context.Context, workspaceID, memberID uuid.UUID) (*model.Membership, error) {
+	member, err := s.workspaceRepo.GetMembership(ctx, workspaceID, memberID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrMemberNotFound)
+	}
+	return member, nil
+}
+
+func (s *workspaceService) checkNotLastOwner(ctx context.Context, workspaceID uuid.UUID) error {
+	owners, err := s.workspaceRepo.CountOwners(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func isOwner(role model.WorkspaceRole) bool {
+	return role == model.WorkspaceRoleOwner
+}
+
+// validateWorkspaceName trims the name and checks its length
+func validateWorkspaceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidWorkspace)
+	}
+	if len([]rune(name)) > maxWorkspaceNameLength {
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidWorkspace, maxWorkspaceNameLength)
+	}
+	return name, nil
+}
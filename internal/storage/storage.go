@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"Arise-test/configs"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("object not found")
+
+// Storage stores file contents under opaque keys. Cancelling the context
+// aborts the operation, including the transfer of the contents.
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any previous object
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object under key; a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the storage backend selected in the configuration
+func New(config configs.StorageConfig) (Storage, error) {
+	switch config.Backend {
+	case "local":
+		return NewLocalStorage(config.LocalDir)
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:        config.S3Endpoint,
+			Region:          config.S3Region,
+			Bucket:          config.S3Bucket,
+			AccessKeyID:     config.S3AccessKeyID,
+			SecretAccessKey: config.S3SecretAccessKey,
+			PathStyle:       config.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", config.Backend)
+	}
+}
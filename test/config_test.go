@@ -0,0 +1,26 @@
+package test
+
+import (
+	"Arise-test/configs"
+	"Arise-test/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatusTransitions(t *testing.T) {
+	transitions, err := configs.ParseStatusTransitions(configs.DefaultStatusTransitions)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TaskStatus{model.TaskStatusPending}, transitions[model.TaskStatusCancelled])
+
+	transitions, err = configs.ParseStatusTransitions("pending=in_progress; in_progress=completed,cancelled")
+	require.NoError(t, err)
+	assert.Len(t, transitions, 2)
+	assert.Equal(t, []model.TaskStatus{model.TaskStatusCompleted, model.TaskStatusCancelled}, transitions[model.TaskStatusInProgress])
+
+	_, err = configs.ParseStatusTransitions("pending=foo")
+	assert.Error(t, err)
+	_, err = configs.ParseStatusTransitions("pending")
+	assert.Error(t, err)
+}
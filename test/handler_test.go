@@ -1,20 +1,27 @@
 package test
 
 import (
+	"Arise-test/configs"
 	"Arise-test/internal/handler"
+	"Arise-test/internal/middleware"
 	"Arise-test/internal/model"
 	"Arise-test/internal/repository"
 	"Arise-test/internal/service"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func setupTestRouter() *gin.Engine {
@@ -25,7 +32,7 @@ func setupTestRouter() *gin.Engine {
 func TestUserHandler_CreateUser(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 	userHandler := handler.NewUserHandler(userService)
 
 	router := setupTestRouter()
@@ -61,7 +68,7 @@ func TestUserHandler_CreateUser(t *testing.T) {
 func TestUserHandler_CreateUser_InvalidData(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 	userHandler := handler.NewUserHandler(userService)
 
 	router := setupTestRouter()
@@ -91,7 +98,7 @@ func TestUserHandler_CreateUser_InvalidData(t *testing.T) {
 func TestUserHandler_GetUser(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 	userHandler := handler.NewUserHandler(userService)
 
 	// Create test user
@@ -102,7 +109,7 @@ func TestUserHandler_GetUser(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	router := setupTestRouter()
@@ -127,7 +134,7 @@ func TestUserHandler_GetUser(t *testing.T) {
 func TestUserHandler_GetUser_NotFound(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 	userHandler := handler.NewUserHandler(userService)
 
 	router := setupTestRouter()
@@ -150,8 +157,8 @@ func TestTaskHandler_CreateTask(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
 	taskHandler := handler.NewTaskHandler(taskService)
 
 	// Create test user first
@@ -162,7 +169,7 @@ func TestTaskHandler_CreateTask(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	router := setupTestRouter()
@@ -203,8 +210,8 @@ func TestTaskHandler_GetTask(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
 	taskHandler := handler.NewTaskHandler(taskService)
 
 	// Create test user first
@@ -215,7 +222,7 @@ func TestTaskHandler_GetTask(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test task
@@ -226,11 +233,15 @@ func TestTaskHandler_GetTask(t *testing.T) {
 		Priority:    model.TaskPriorityMedium,
 		UserID:      user.ID,
 	}
-	err = taskService.CreateTask(task)
+	err = taskService.CreateTask(ctx, task)
 	require.NoError(t, err)
 
 	router := setupTestRouter()
-	router.GET("/tasks/:id", taskHandler.GetTask)
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", user.ID)
+		taskHandler.GetTask(c)
+	})
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/tasks/%s", task.ID), nil)
 	w := httptest.NewRecorder()
@@ -248,12 +259,170 @@ func TestTaskHandler_GetTask(t *testing.T) {
 	assert.Equal(t, "pending", taskData["status"])
 }
 
+func TestTaskHandler_GetTask_OtherUser(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+	taskHandler := handler.NewTaskHandler(taskService)
+
+	// Create test user first
+	user := &model.User{
+		Username:  "testuser",
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+	}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	task := &model.Task{
+		Title:  "Private Task",
+		UserID: user.ID,
+	}
+	err = taskService.CreateTask(ctx, task)
+	require.NoError(t, err)
+
+	router := setupTestRouter()
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		// Authenticated as someone else
+		c.Set("userID", uuid.New())
+		taskHandler.GetTask(c)
+	})
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/tasks/%s", task.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTaskHandler_GetUserTasks_InvalidFilter(t *testing.T) {
+	// Invalid parameters are rejected before the service is called
+	taskHandler := handler.NewTaskHandler(nil)
+	router := setupTestRouter()
+	router.GET("/tasks", func(c *gin.Context) {
+		c.Set("userID", uuid.New())
+		taskHandler.GetUserTasks(c)
+	})
+
+	for _, query := range []string{
+		"limit=ten",
+		"due_before=tomorrow",
+		"category_id=abc",
+		"tag_id=abc",
+		"tag_match=some",
+		"overdue=maybe",
+		"order=sideways",
+		"limit=0",
+		"include_total=sometimes",
+	} {
+		req, _ := http.NewRequest("GET", "/tasks?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestPatchTaskRequest(t *testing.T) {
+	var req handler.PatchTaskRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"title": "Renamed", "due_date": null}`), &req))
+
+	assert.True(t, req.Title.Set)
+	assert.Equal(t, "Renamed", req.Title.Value)
+	// An explicit null is present but empty, an absent member is not present
+	assert.True(t, req.DueDate.Set)
+	assert.Nil(t, req.DueDate.Value)
+	assert.False(t, req.Description.Set)
+}
+
+func TestTaskHandler_PatchTask(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskService := newTestTaskService(repository.NewTaskRepository(db), db)
+	taskHandler := handler.NewTaskHandler(taskService)
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	dueDate := time.Now().Add(24 * time.Hour)
+	task := &model.Task{
+		Title:       "Test Task",
+		Description: "This is a test task",
+		Priority:    model.TaskPriorityLow,
+		DueDate:     &dueDate,
+		UserID:      user.ID,
+	}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+
+	router := setupTestRouter()
+	router.PATCH("/tasks/:id", func(c *gin.Context) {
+		c.Set("userID", user.ID)
+		taskHandler.PatchTask(c)
+	})
+	patch := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", fmt.Sprintf("/tasks/%s", task.ID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch(`{"description": null, "due_date": null, "priority": "high"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	found, err := taskService.GetTaskByID(ctx, user.ID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Test Task", found.Title)
+	assert.Empty(t, found.Description)
+	assert.Nil(t, found.DueDate)
+	assert.Equal(t, model.TaskPriorityHigh, found.Priority)
+
+	// Required fields cannot be cleared and values are still validated
+	assert.Equal(t, http.StatusBadRequest, patch(`{"title": null}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch(`{"priority": "someday"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch(`{"due_date": "tomorrow"}`).Code)
+
+	// The response carries the category the task now has
+	categoryRepo := repository.NewCategoryRepository(db)
+	work := &model.Category{Name: "Work", UserID: user.ID}
+	require.NoError(t, categoryRepo.Create(ctx, work))
+	home := &model.Category{Name: "Home", UserID: user.ID}
+	require.NoError(t, categoryRepo.Create(ctx, home))
+	var response struct {
+		Task struct {
+			Category *model.Category `json:"category"`
+		} `json:"task"`
+	}
+	for _, category := range []*model.Category{work, home} {
+		w = patch(fmt.Sprintf(`{"category_id": %q}`, category.ID))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotNil(t, response.Task.Category)
+		assert.Equal(t, category.ID, response.Task.Category.ID)
+	}
+	w = patch(`{"category_id": null}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	response.Task.Category = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Nil(t, response.Task.Category)
+}
+
 func TestTaskHandler_GetUserTasks(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
 	taskHandler := handler.NewTaskHandler(taskService)
 
 	// Create test user first
@@ -264,7 +433,7 @@ func TestTaskHandler_GetUserTasks(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create multiple tasks
@@ -275,7 +444,7 @@ func TestTaskHandler_GetUserTasks(t *testing.T) {
 			Priority: model.TaskPriorityMedium,
 			UserID:   user.ID,
 		}
-		err := taskService.CreateTask(task)
+		err := taskService.CreateTask(ctx, task)
 		require.NoError(t, err)
 	}
 
@@ -296,9 +465,26 @@ func TestTaskHandler_GetUserTasks(t *testing.T) {
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
 
-	tasks, ok := response["tasks"].([]interface{})
+	tasks, ok := response["items"].([]interface{})
 	require.True(t, ok)
 	assert.Len(t, tasks, 3)
+	assert.Equal(t, false, response["has_more"])
+	assert.Empty(t, w.Header().Get("Link"))
+
+	// A smaller page links to the next one
+	req, _ = http.NewRequest("GET", "/tasks?limit=2&include_total=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	response = map[string]interface{}{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Len(t, response["items"], 2)
+	assert.Equal(t, true, response["has_more"])
+	assert.Equal(t, float64(3), response["total"])
+	assert.Contains(t, w.Header().Get("Link"), fmt.Sprintf("cursor=%s", response["next_cursor"]))
+	assert.Contains(t, w.Header().Get("Link"), `rel="next"`)
 }
 
 func TestCategoryHandler_CreateCategory(t *testing.T) {
@@ -308,8 +494,8 @@ func TestCategoryHandler_CreateCategory(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	// Create test user first
@@ -320,11 +506,15 @@ func TestCategoryHandler_CreateCategory(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	router := setupTestRouter()
-	router.POST("/categories", categoryHandler.CreateCategory)
+	router.POST("/categories", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", user.ID)
+		categoryHandler.CreateCategory(c)
+	})
 
 	category := map[string]interface{}{
 		"name":        "Work Projects",
@@ -333,7 +523,7 @@ func TestCategoryHandler_CreateCategory(t *testing.T) {
 	}
 
 	jsonData, _ := json.Marshal(category)
-	req, _ := http.NewRequest("POST", fmt.Sprintf("/categories?user_id=%s", user.ID), bytes.NewBuffer(jsonData))
+	req, _ := http.NewRequest("POST", "/categories", bytes.NewBuffer(jsonData))
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -359,8 +549,8 @@ func TestCategoryHandler_CreateCategory_InvalidData(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	// Create test user first
@@ -371,11 +561,15 @@ func TestCategoryHandler_CreateCategory_InvalidData(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	router := setupTestRouter()
-	router.POST("/categories", categoryHandler.CreateCategory)
+	router.POST("/categories", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", user.ID)
+		categoryHandler.CreateCategory(c)
+	})
 
 	// Missing required fields
 	category := map[string]interface{}{
@@ -384,7 +578,7 @@ func TestCategoryHandler_CreateCategory_InvalidData(t *testing.T) {
 	}
 
 	jsonData, _ := json.Marshal(category)
-	req, _ := http.NewRequest("POST", fmt.Sprintf("/categories?user_id=%s", user.ID), bytes.NewBuffer(jsonData))
+	req, _ := http.NewRequest("POST", "/categories", bytes.NewBuffer(jsonData))
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -405,8 +599,8 @@ func TestCategoryHandler_GetCategory(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	// Create test user first
@@ -417,7 +611,7 @@ func TestCategoryHandler_GetCategory(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test category
@@ -427,11 +621,15 @@ func TestCategoryHandler_GetCategory(t *testing.T) {
 		Color:       "#9C27B0",
 		UserID:      user.ID,
 	}
-	err = categoryService.CreateCategory(category)
+	err = categoryService.CreateCategory(ctx, category)
 	require.NoError(t, err)
 
 	router := setupTestRouter()
-	router.GET("/categories/:id", categoryHandler.GetCategory)
+	router.GET("/categories/:id", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", user.ID)
+		categoryHandler.GetCategory(c)
+	})
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/categories/%s", category.ID), nil)
 	w := httptest.NewRecorder()
@@ -456,11 +654,15 @@ func TestCategoryHandler_GetCategory_NotFound(t *testing.T) {
 		t.Skip("Test database not available")
 	}
 	categoryRepo := repository.NewCategoryRepository(db)
-	categoryService := service.NewCategoryService(categoryRepo)
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	router := setupTestRouter()
-	router.GET("/categories/:id", categoryHandler.GetCategory)
+	router.GET("/categories/:id", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", uuid.New())
+		categoryHandler.GetCategory(c)
+	})
 
 	// Use a non-existent UUID
 	req, _ := http.NewRequest("GET", "/categories/550e8400-e29b-41d4-a716-446655440000", nil)
@@ -482,8 +684,8 @@ func TestCategoryHandler_GetUserCategories(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	// Create test user first
@@ -494,7 +696,7 @@ func TestCategoryHandler_GetUserCategories(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create multiple categories
@@ -504,14 +706,18 @@ func TestCategoryHandler_GetUserCategories(t *testing.T) {
 			Name:   name,
 			UserID: user.ID,
 		}
-		err := categoryService.CreateCategory(category)
+		err := categoryService.CreateCategory(ctx, category)
 		require.NoError(t, err)
 	}
 
 	router := setupTestRouter()
-	router.GET("/categories", categoryHandler.GetUserCategories)
+	router.GET("/categories", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", user.ID)
+		categoryHandler.GetUserCategories(c)
+	})
 
-	req, _ := http.NewRequest("GET", fmt.Sprintf("/categories?user_id=%s", user.ID), nil)
+	req, _ := http.NewRequest("GET", "/categories", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -533,8 +739,8 @@ func TestCategoryHandler_UpdateCategory(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	// Create test user first
@@ -545,7 +751,7 @@ func TestCategoryHandler_UpdateCategory(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test category
@@ -555,11 +761,15 @@ func TestCategoryHandler_UpdateCategory(t *testing.T) {
 		Color:       "#000000",
 		UserID:      user.ID,
 	}
-	err = categoryService.CreateCategory(category)
+	err = categoryService.CreateCategory(ctx, category)
 	require.NoError(t, err)
 
 	router := setupTestRouter()
-	router.PUT("/categories/:id", categoryHandler.UpdateCategory)
+	router.PUT("/categories/:id", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", user.ID)
+		categoryHandler.UpdateCategory(c)
+	})
 
 	updateData := map[string]interface{}{
 		"name":        "Updated Name",
@@ -587,6 +797,171 @@ func TestCategoryHandler_UpdateCategory(t *testing.T) {
 	assert.Equal(t, "#FF5722", categoryData["color"])
 }
 
+func TestCategoryHandler_ConditionalRequests(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db), repository.NewTxManager(db))
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, user))
+	category := &model.Category{Name: "Work", UserID: user.ID}
+	require.NoError(t, categoryService.CreateCategory(ctx, category))
+
+	router := setupTestRouter()
+	setUser := func(c *gin.Context) { c.Set("userID", user.ID) }
+	router.GET("/categories/:id", setUser, categoryHandler.GetCategory)
+	router.PATCH("/categories/:id", setUser, categoryHandler.PatchCategory)
+	send := func(method, header, value, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, fmt.Sprintf("/categories/%s", category.ID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("GET", "", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	tag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, tag)
+
+	// Unchanged resources are not sent again
+	assert.Equal(t, http.StatusNotModified, send("GET", "If-None-Match", tag, "").Code)
+
+	w = send("PATCH", "If-Match", tag, `{"name": "Office"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	// A write based on the old version is refused
+	assert.Equal(t, http.StatusPreconditionFailed, send("PATCH", "If-Match", tag, `{"name": "Home"}`).Code)
+	assert.Equal(t, http.StatusOK, send("GET", "If-None-Match", tag, "").Code)
+}
+
+func TestIdempotency(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db), repository.NewTxManager(db))
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+	idempotencyService := service.NewIdempotencyService(repository.NewIdempotencyRepository(db), configs.IdempotencyConfig{TTL: time.Hour})
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	router := setupTestRouter()
+	router.POST("/categories", func(c *gin.Context) {
+		c.Set("userID", user.ID)
+	}, middleware.Idempotency(idempotencyService), categoryHandler.CreateCategory)
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/categories", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post("create-work", `{"name": "Work"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	// A retry gets the stored response instead of creating a duplicate
+	retry := post("create-work", `{"name": "Work"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+
+	categories, err := categoryService.GetCategoriesByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, categories, 1)
+
+	// The key cannot be reused for another request
+	assert.Equal(t, http.StatusConflict, post("create-work", `{"name": "Home"}`).Code)
+
+	// Failed requests are stored too, as they would fail again
+	assert.Equal(t, http.StatusBadRequest, post("create-nameless", `{}`).Code)
+	assert.Equal(t, "true", post("create-nameless", `{}`).Header().Get(middleware.IdempotentReplayedHeader))
+
+	// Anonymous keys are scoped to the client and replay the response headers
+	created := 0
+	router.POST("/anonymous", middleware.Idempotency(idempotencyService), func(c *gin.Context) {
+		created++
+		c.Header("ETag", `"1"`)
+		c.Header("Location", fmt.Sprintf("/anonymous/%d", created))
+		c.JSON(http.StatusCreated, gin.H{"created": created})
+	})
+	anonymous := func(client, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/anonymous", bytes.NewBufferString(body))
+		req.RemoteAddr = client + ":40000"
+		req.Header.Set(middleware.IdempotencyKeyHeader, "signup")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, anonymous("192.0.2.1", `{"name": "a"}`).Code)
+	replayed := anonymous("192.0.2.1", `{"name": "a"}`)
+	assert.Equal(t, "true", replayed.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, `"1"`, replayed.Header().Get("ETag"))
+	assert.Equal(t, "/anonymous/1", replayed.Header().Get("Location"))
+	assert.Equal(t, http.StatusConflict, anonymous("192.0.2.1", `{"name": "b"}`).Code)
+	other := anonymous("192.0.2.2", `{"name": "a"}`)
+	assert.Equal(t, http.StatusCreated, other.Code)
+	assert.Empty(t, other.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 2, created)
+}
+
+func TestRequireIfMatch(t *testing.T) {
+	router := setupTestRouter()
+	router.PUT("/required", middleware.RequireIfMatch(true), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.PUT("/optional", middleware.RequireIfMatch(false), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, tc := range []struct {
+		path    string
+		ifMatch string
+		status  int
+	}{
+		{"/required", "", http.StatusPreconditionRequired},
+		{"/required", `"1"`, http.StatusNoContent},
+		{"/optional", "", http.StatusNoContent},
+	} {
+		req, _ := http.NewRequest("PUT", tc.path, nil)
+		if tc.ifMatch != "" {
+			req.Header.Set("If-Match", tc.ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.status, w.Code, tc.path)
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	router := setupTestRouter()
+	deadline := func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, gin.H{"deadline": ok})
+	}
+	router.GET("/bounded", middleware.QueryTimeout(time.Second), deadline)
+	router.GET("/unbounded", middleware.QueryTimeout(0), deadline)
+
+	for path, expected := range map[string]string{
+		"/bounded":   `{"deadline":true}`,
+		"/unbounded": `{"deadline":false}`,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.JSONEq(t, expected, w.Body.String(), path)
+	}
+}
+
 func TestCategoryHandler_DeleteCategory(t *testing.T) {
 	db := setupTestDB()
 	if db == nil {
@@ -594,8 +969,8 @@ func TestCategoryHandler_DeleteCategory(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	// Create test user first
@@ -606,7 +981,7 @@ func TestCategoryHandler_DeleteCategory(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test category
@@ -615,15 +990,27 @@ func TestCategoryHandler_DeleteCategory(t *testing.T) {
 		Description: "This category will be deleted",
 		UserID:      user.ID,
 	}
-	err = categoryService.CreateCategory(category)
+	err = categoryService.CreateCategory(ctx, category)
 	require.NoError(t, err)
 
 	router := setupTestRouter()
-	router.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+	router.DELETE("/categories/:id", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", user.ID)
+		categoryHandler.DeleteCategory(c)
+	})
 
+	// A stale If-Match leaves the category in place
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/categories/%s", category.ID), nil)
+	req.Header.Set("If-Match", `"2"`)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/categories/%s", category.ID), nil)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
@@ -633,3 +1020,109 @@ func TestCategoryHandler_DeleteCategory(t *testing.T) {
 	assert.Contains(t, response, "message")
 	assert.Equal(t, "category deleted successfully", response["message"])
 }
+
+// knownUsers is a UserService that only finds the user with its ID
+type knownUsers struct {
+	service.UserService
+	id uuid.UUID
+}
+
+func (k knownUsers) GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	if id != k.id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.User{ID: id}, nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	userID := uuid.New()
+	authService := service.NewAuthService(knownUsers{id: userID}, nil, configs.SecurityConfig{
+		JWTSecret:      "test-secret",
+		AccessTokenTTL: 15 * time.Minute,
+	})
+
+	claims := jwt.RegisteredClaims{
+		Subject:   userID.String(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+	validToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+	forgedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("other-secret"))
+	require.NoError(t, err)
+	claims.Subject = uuid.NewString()
+	deletedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	router := setupTestRouter()
+	router.GET("/protected", middleware.AuthMiddleware(authService), func(c *gin.Context) {
+		id, _ := c.Get("userID")
+		c.JSON(http.StatusOK, gin.H{"user_id": id})
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + validToken, http.StatusUnauthorized},
+		{"forged token", "Bearer " + forgedToken, http.StatusUnauthorized},
+		{"deleted user", "Bearer " + deletedToken, http.StatusUnauthorized},
+		{"valid token", "Bearer " + validToken, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/protected", nil)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.code, w.Code)
+			if test.code == http.StatusOK {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				require.NoError(t, err)
+				assert.Equal(t, userID.String(), response["user_id"])
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	roleService := service.NewRoleService(repository.NewRoleRepository(db), userRepo)
+	userHandler := handler.NewUserHandler(userService)
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	router := setupTestRouter()
+	router.GET("/admin/users", func(c *gin.Context) {
+		// Set userID in context (simulate auth middleware)
+		c.Set("userID", user.ID)
+	}, middleware.RequirePermission(roleService, model.PermissionUsersList), userHandler.ListUsers)
+
+	// Ordinary user is forbidden
+	req, _ := http.NewRequest("GET", "/admin/users", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Admin is allowed
+	err = roleService.AssignRole(ctx, user.ID, model.RoleAdmin)
+	require.NoError(t, err)
+
+	req, _ = http.NewRequest("GET", "/admin/users", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
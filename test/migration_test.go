@@ -0,0 +1,130 @@
+package test
+
+import (
+	"Arise-test/internal/migrations"
+	"Arise-test/internal/model"
+	"Arise-test/internal/repository"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// baselineUser, baselineTask and baselineCategory are the models as they were
+// when the schema was still created by GORM AutoMigrate
+type baselineUser struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;"`
+	Username  string    `gorm:"uniqueIndex;not null"`
+	Email     string    `gorm:"uniqueIndex;not null"`
+	Password  string    `gorm:"not null"`
+	FirstName string
+	LastName  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Tasks []baselineTask `gorm:"foreignKey:UserID"`
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineTask struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;"`
+	Title       string    `gorm:"not null"`
+	Description string
+	Status      string `gorm:"default:'pending'"`
+	Priority    string `gorm:"default:'medium'"`
+	DueDate     *time.Time
+	UserID      uuid.UUID  `gorm:"type:uuid;not null"`
+	CategoryID  *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+
+	User     baselineUser      `gorm:"foreignKey:UserID"`
+	Category *baselineCategory `gorm:"foreignKey:CategoryID"`
+}
+
+func (baselineTask) TableName() string { return "tasks" }
+
+type baselineCategory struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;"`
+	Name        string    `gorm:"not null"`
+	Description string
+	Color       string
+	UserID      uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+
+	User  baselineUser   `gorm:"foreignKey:UserID"`
+	Tasks []baselineTask `gorm:"foreignKey:CategoryID"`
+}
+
+func (baselineCategory) TableName() string { return "categories" }
+
+func TestMigrations_Load(t *testing.T) {
+	loaded, err := migrations.Load()
+
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	for i, migration := range loaded {
+		assert.NotEmpty(t, migration.Up, migration.Name)
+		assert.NotEmpty(t, migration.Down, migration.Name)
+		if i > 0 {
+			assert.Greater(t, migration.Version, loaded[i-1].Version)
+		}
+	}
+}
+
+func TestMigrations_Create(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "0007_existing.up.sql"), nil, 0o644)
+	require.NoError(t, err)
+
+	paths, err := migrations.Create(dir, "add_things")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "0008_add_things.up.sql"),
+		filepath.Join(dir, "0008_add_things.down.sql"),
+	}, paths)
+
+	_, err = migrations.Create(dir, "Bad Name")
+	assert.Error(t, err)
+}
+
+func TestMigrations_AdoptAutoMigrateSchema(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+
+	// Start over from the schema the baseline created with AutoMigrate
+	require.NoError(t, db.Exec("DROP SCHEMA public CASCADE").Error)
+	require.NoError(t, db.Exec("CREATE SCHEMA public").Error)
+	require.NoError(t, db.AutoMigrate(&baselineUser{}, &baselineCategory{}, &baselineTask{}))
+	existing := &baselineUser{ID: uuid.New(), Username: "existing", Email: "existing@example.com", Password: "password123"}
+	require.NoError(t, db.Create(existing).Error)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	migrator, err := migrations.NewMigrator(sqlDB)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	user, err := repository.NewUserRepository(db).GetByID(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.RoleUser, user.Role)
+
+	category := &model.Category{Name: "Work", UserID: user.ID}
+	require.NoError(t, db.Create(category).Error)
+	task := &model.Task{Title: "Task", UserID: user.ID, CategoryID: &category.ID}
+	require.NoError(t, db.Create(task).Error)
+}
@@ -128,3 +128,52 @@ func TestTaskModel_DefaultValues(t *testing.T) {
 	assert.Empty(t, task.Status)   // Will be set to default by GORM
 	assert.Empty(t, task.Priority) // Will be set to default by GORM
 }
+
+func TestPermission_IsValid(t *testing.T) {
+	for _, permission := range model.AllPermissions {
+		assert.True(t, permission.IsValid(), string(permission))
+	}
+	assert.False(t, model.Permission("tasks:destroy_everything").IsValid())
+}
+
+func TestTaskStatus_IsValid(t *testing.T) {
+	assert.True(t, model.TaskStatusPending.IsValid())
+	assert.True(t, model.TaskStatusCancelled.IsValid())
+	assert.False(t, model.TaskStatus("foo").IsValid())
+	assert.False(t, model.TaskStatus("").IsValid())
+}
+
+func TestTaskPermission_Includes(t *testing.T) {
+	assert.True(t, model.TaskPermissionOwner.Includes(model.TaskPermissionEdit))
+	assert.True(t, model.TaskPermissionEdit.Includes(model.TaskPermissionEdit))
+	assert.True(t, model.TaskPermissionEdit.Includes(model.TaskPermissionView))
+	assert.False(t, model.TaskPermissionView.Includes(model.TaskPermissionEdit))
+	assert.False(t, model.TaskPermissionEdit.Includes(model.TaskPermissionOwner))
+	assert.False(t, model.TaskPermission("admin").Includes(model.TaskPermissionView))
+	assert.False(t, model.TaskPermissionOwner.Includes(model.TaskPermission("")))
+
+	assert.True(t, model.TaskPermissionView.IsShareable())
+	assert.False(t, model.TaskPermissionOwner.IsShareable())
+}
+
+func TestWorkspaceRole(t *testing.T) {
+	assert.True(t, model.WorkspaceRoleGuest.IsValid())
+	assert.False(t, model.WorkspaceRole("superuser").IsValid())
+
+	assert.True(t, model.WorkspaceRoleAdmin.CanManage())
+	assert.False(t, model.WorkspaceRoleMember.CanManage())
+	assert.True(t, model.WorkspaceRoleMember.CanContribute())
+	assert.False(t, model.WorkspaceRoleGuest.CanContribute())
+}
+
+func TestDeletePolicies(t *testing.T) {
+	assert.True(t, model.CategoryDeleteReassign.IsValid())
+	assert.False(t, model.CategoryDeletePolicy("archive").IsValid())
+	assert.True(t, model.UserDeleteCascade.IsValid())
+	assert.False(t, model.UserDeletePolicy("purge").IsValid())
+}
+
+func TestProjectStatus(t *testing.T) {
+	assert.True(t, model.ProjectStatusOnHold.IsValid())
+	assert.False(t, model.ProjectStatus("archived").IsValid())
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"Arise-test/internal/rank"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRank_Between(t *testing.T) {
+	for _, bounds := range [][2]string{
+		{"", ""},
+		{"", "i"},
+		{"i", ""},
+		{"a", "b"},
+		{"a", "a1"},
+		{"az", "b"},
+		{"zz", ""},
+		{"", "01"},
+	} {
+		got, err := rank.Between(bounds[0], bounds[1])
+		require.NoError(t, err, bounds)
+		assert.Greater(t, got, bounds[0], bounds)
+		if bounds[1] != "" {
+			assert.Less(t, got, bounds[1], bounds)
+		}
+	}
+}
+
+func TestRank_Between_Invalid(t *testing.T) {
+	for _, bounds := range [][2]string{
+		{"b", "a"},
+		{"a", "a"},
+		{"A", ""},
+		{"", "0"},
+	} {
+		_, err := rank.Between(bounds[0], bounds[1])
+		assert.ErrorIs(t, err, rank.ErrInvalidRange, bounds)
+	}
+}
+
+func TestRank_RepeatedInserts(t *testing.T) {
+	// Inserting again and again at the same place keeps the order without
+	// touching the neighbours
+	prev, next := "", "i"
+	for i := 0; i < 200; i++ {
+		got, err := rank.Between(prev, next)
+		require.NoError(t, err)
+		require.Greater(t, got, prev)
+		require.Less(t, got, next)
+		next = got
+	}
+
+	last := ""
+	for i := 0; i < 200; i++ {
+		got, err := rank.After(last)
+		require.NoError(t, err)
+		require.Greater(t, got, last)
+		last = got
+	}
+}
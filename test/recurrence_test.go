@@ -0,0 +1,80 @@
+package test
+
+import (
+	"Arise-test/internal/recurrence"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrence_Parse_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=YEARLY;INTERVAL=100000",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250101",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		_, err := recurrence.Parse(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		rule       string
+		current    time.Time
+		occurrence int
+		want       time.Time
+		ok         bool
+	}{
+		{"FREQ=DAILY;INTERVAL=3", date(2025, 1, 30), 1, date(2025, 2, 2), true},
+		// Wednesday, then Friday, then Monday two weeks on
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR", date(2025, 1, 1), 1, date(2025, 1, 3), true},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR", date(2025, 1, 3), 2, date(2025, 1, 13), true},
+		{"FREQ=WEEKLY", date(2025, 1, 1), 1, date(2025, 1, 8), true},
+		// February has no 31st, so it is skipped
+		{"FREQ=MONTHLY", date(2025, 1, 31), 1, date(2025, 3, 31), true},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", date(2025, 1, 31), 1, date(2025, 2, 28), true},
+		{"FREQ=YEARLY", date(2024, 2, 29), 1, date(2028, 2, 29), true},
+		{"FREQ=MONTHLY;INTERVAL=13;BYMONTHDAY=1", date(2025, 1, 15), 1, date(2026, 2, 1), true},
+		{"FREQ=YEARLY;INTERVAL=366;BYDAY=MO", date(2025, 1, 6), 1, date(2025, 1, 13), true},
+		{"RRULE:FREQ=DAILY;COUNT=3", date(2025, 1, 1), 2, date(2025, 1, 2), true},
+		{"FREQ=DAILY;COUNT=3", date(2025, 1, 3), 3, time.Time{}, false},
+		{"FREQ=DAILY;UNTIL=20250102", date(2025, 1, 1), 1, date(2025, 1, 2), true},
+		{"FREQ=DAILY;UNTIL=20250102", date(2025, 1, 2), 2, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		rule, err := recurrence.Parse(tt.rule)
+		require.NoError(t, err, tt.rule)
+
+		next, ok := rule.Next(tt.current, tt.occurrence)
+		assert.Equal(t, tt.ok, ok, tt.rule)
+		assert.Equal(t, tt.want, next, tt.rule)
+	}
+}
+
+func TestRecurrence_Repeats(t *testing.T) {
+	february := time.Date(2025, 2, 10, 9, 0, 0, 0, time.UTC)
+
+	// Every 12 months from February only ever sees February
+	never, err := recurrence.Parse("FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30")
+	require.NoError(t, err)
+	assert.False(t, never.Repeats(february))
+
+	// A rule exhausted by COUNT still repeats in principle
+	once, err := recurrence.Parse("FREQ=DAILY;COUNT=1")
+	require.NoError(t, err)
+	assert.True(t, once.Repeats(february))
+}
@@ -1,21 +1,29 @@
 package test
 
 import (
+	"Arise-test/configs"
 	"Arise-test/internal/model"
 	"Arise-test/internal/repository"
 	"Arise-test/internal/service"
+	"Arise-test/internal/storage"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 func TestUserService_CreateUser(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 
 	user := &model.User{
 		Username:  "testuser",
@@ -25,7 +33,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		LastName:  "User",
 	}
 
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 
 	require.NoError(t, err)
 	assert.Equal(t, "testuser", user.Username)
@@ -40,7 +48,7 @@ func TestUserService_CreateUser(t *testing.T) {
 func TestUserService_CreateUser_DuplicateEmail(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 
 	// Create first user
 	user1 := &model.User{
@@ -50,7 +58,7 @@ func TestUserService_CreateUser_DuplicateEmail(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user1)
+	err := userService.CreateUser(ctx, user1)
 	require.NoError(t, err)
 
 	// Try to create second user with same email
@@ -61,7 +69,7 @@ func TestUserService_CreateUser_DuplicateEmail(t *testing.T) {
 		FirstName: "Another",
 		LastName:  "User",
 	}
-	err = userService.CreateUser(user2)
+	err = userService.CreateUser(ctx, user2)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "user with this email already exists")
@@ -70,7 +78,7 @@ func TestUserService_CreateUser_DuplicateEmail(t *testing.T) {
 func TestUserService_GetUserByEmail(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -80,11 +88,11 @@ func TestUserService_GetUserByEmail(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Get user by email
-	foundUser, err := userService.GetUserByEmail("test@example.com")
+	foundUser, err := userService.GetUserByEmail(ctx, "test@example.com")
 
 	require.NoError(t, err)
 	assert.Equal(t, user.ID, foundUser.ID)
@@ -94,7 +102,7 @@ func TestUserService_GetUserByEmail(t *testing.T) {
 func TestUserService_GetUserByID(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -104,11 +112,11 @@ func TestUserService_GetUserByID(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Get user by ID
-	foundUser, err := userService.GetUserByID(user.ID)
+	foundUser, err := userService.GetUserByID(ctx, user.ID)
 
 	require.NoError(t, err)
 	assert.Equal(t, user.ID, foundUser.ID)
@@ -118,7 +126,7 @@ func TestUserService_GetUserByID(t *testing.T) {
 func TestUserService_UpdateUser(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -128,13 +136,18 @@ func TestUserService_UpdateUser(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
+	// Other users learn nothing about the account, not even its version
+	stale := 5
+	assert.ErrorIs(t, userService.UpdateUser(ctx, uuid.New(), user, &stale), service.ErrUserNotFound)
+	assert.ErrorIs(t, userService.UpdateUser(ctx, user.ID, user, &stale), repository.ErrVersionConflict)
+
 	// Update user
 	user.FirstName = "Updated"
 	user.LastName = "Name"
-	err = userService.UpdateUser(user)
+	err = userService.UpdateUser(ctx, user.ID, user, &user.Version)
 
 	require.NoError(t, err)
 	assert.Equal(t, "Updated", user.FirstName)
@@ -145,8 +158,8 @@ func TestTaskService_CreateTask(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
 
 	// Create test user
 	user := &model.User{
@@ -156,7 +169,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test task
@@ -170,7 +183,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		UserID:      user.ID,
 	}
 
-	err = taskService.CreateTask(task)
+	err = taskService.CreateTask(ctx, task)
 
 	require.NoError(t, err)
 	assert.Equal(t, "Test Task", task.Title)
@@ -182,8 +195,8 @@ func TestTaskService_GetTaskByID(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
 
 	// Create test user
 	user := &model.User{
@@ -193,7 +206,7 @@ func TestTaskService_GetTaskByID(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test task
@@ -204,11 +217,11 @@ func TestTaskService_GetTaskByID(t *testing.T) {
 		Priority:    model.TaskPriorityMedium,
 		UserID:      user.ID,
 	}
-	err = taskService.CreateTask(task)
+	err = taskService.CreateTask(ctx, task)
 	require.NoError(t, err)
 
 	// Get task by ID
-	foundTask, err := taskService.GetTaskByID(task.ID)
+	foundTask, err := taskService.GetTaskByID(ctx, user.ID, task.ID)
 
 	require.NoError(t, err)
 	assert.Equal(t, task.ID, foundTask.ID)
@@ -219,8 +232,8 @@ func TestTaskService_GetTasksByUserID(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
 
 	// Create test user
 	user := &model.User{
@@ -230,7 +243,7 @@ func TestTaskService_GetTasksByUserID(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create multiple tasks
@@ -241,12 +254,12 @@ func TestTaskService_GetTasksByUserID(t *testing.T) {
 			Priority: model.TaskPriorityMedium,
 			UserID:   user.ID,
 		}
-		err := taskService.CreateTask(task)
+		err := taskService.CreateTask(ctx, task)
 		require.NoError(t, err)
 	}
 
 	// Get user tasks
-	tasks, err := taskService.GetTasksByUserID(user.ID, 10, 0)
+	tasks, err := taskService.GetTasksByUserID(ctx, user.ID, 10, 0)
 
 	require.NoError(t, err)
 	assert.Len(t, tasks, 3)
@@ -259,8 +272,8 @@ func TestTaskService_UpdateTask(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
 
 	// Create test user
 	user := &model.User{
@@ -270,7 +283,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test task
@@ -281,13 +294,13 @@ func TestTaskService_UpdateTask(t *testing.T) {
 		Priority:    model.TaskPriorityMedium,
 		UserID:      user.ID,
 	}
-	err = taskService.CreateTask(task)
+	err = taskService.CreateTask(ctx, task)
 	require.NoError(t, err)
 
 	// Update task
 	task.Title = "Updated Task"
 	task.Status = model.TaskStatusCompleted
-	err = taskService.UpdateTask(task)
+	err = taskService.UpdateTask(ctx, user.ID, task)
 
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Task", task.Title)
@@ -298,8 +311,8 @@ func TestTaskService_DeleteTask(t *testing.T) {
 	db := setupTestDB()
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
-	userService := service.NewUserService(userRepo)
-	taskService := service.NewTaskService(taskRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
 
 	// Create test user
 	user := &model.User{
@@ -309,7 +322,7 @@ func TestTaskService_DeleteTask(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test task
@@ -320,19 +333,348 @@ func TestTaskService_DeleteTask(t *testing.T) {
 		Priority:    model.TaskPriorityMedium,
 		UserID:      user.ID,
 	}
-	err = taskService.CreateTask(task)
+	err = taskService.CreateTask(ctx, task)
 	require.NoError(t, err)
 
 	// Delete task
-	err = taskService.DeleteTask(task.ID)
+	err = taskService.DeleteTask(ctx, user.ID, task.ID, nil)
 	require.NoError(t, err)
 
 	// Verify task is deleted
-	foundTask, err := taskService.GetTaskByID(task.ID)
+	foundTask, err := taskService.GetTaskByID(ctx, user.ID, task.ID)
 	assert.Error(t, err)
 	assert.Nil(t, foundTask)
 }
 
+func TestTaskService_OtherUsersTask(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+
+	// Create owner and another user
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	err := userService.CreateUser(ctx, owner)
+	require.NoError(t, err)
+	other := &model.User{Username: "other", Email: "other@example.com", Password: "password123"}
+	err = userService.CreateUser(ctx, other)
+	require.NoError(t, err)
+
+	task := &model.Task{
+		Title:  "Owner Task",
+		UserID: owner.ID,
+	}
+	err = taskService.CreateTask(ctx, task)
+	require.NoError(t, err)
+
+	// Another user sees the task as not found
+	_, err = taskService.GetTaskByID(ctx, other.ID, task.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+
+	task.Title = "Hijacked"
+	err = taskService.UpdateTask(ctx, other.ID, task)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+
+	err = taskService.DeleteTask(ctx, other.ID, task.ID, nil)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+
+	// The task is untouched
+	foundTask, err := taskService.GetTaskByID(ctx, owner.ID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Owner Task", foundTask.Title)
+}
+
+func TestTaskService_CreateTask_OtherUsersCategory(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	err := userService.CreateUser(ctx, owner)
+	require.NoError(t, err)
+	other := &model.User{Username: "other", Email: "other@example.com", Password: "password123"}
+	err = userService.CreateUser(ctx, other)
+	require.NoError(t, err)
+
+	category := &model.Category{Name: "Owner Category", UserID: owner.ID}
+	err = categoryService.CreateCategory(ctx, category)
+	require.NoError(t, err)
+
+	task := &model.Task{
+		Title:      "Sneaky Task",
+		UserID:     other.ID,
+		CategoryID: &category.ID,
+	}
+	err = taskService.CreateTask(ctx, task)
+	assert.ErrorIs(t, err, service.ErrCategoryNotFound)
+}
+
+func TestTaskService_Subtasks(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	// parent -> child -> grandchild, parent -> done
+	parent := &model.Task{Title: "Parent", UserID: user.ID, Status: model.TaskStatusPending}
+	require.NoError(t, taskService.CreateTask(ctx, parent))
+	child := &model.Task{Title: "Child", UserID: user.ID, Status: model.TaskStatusPending, ParentID: &parent.ID}
+	require.NoError(t, taskService.CreateTask(ctx, child))
+	grandchild := &model.Task{Title: "Grandchild", UserID: user.ID, Status: model.TaskStatusPending, ParentID: &child.ID}
+	require.NoError(t, taskService.CreateTask(ctx, grandchild))
+	done := &model.Task{Title: "Done", UserID: user.ID, Status: model.TaskStatusCompleted, ParentID: &parent.ID}
+	require.NoError(t, taskService.CreateTask(ctx, done))
+
+	children, err := taskService.GetSubtasks(ctx, user.ID, parent.ID)
+	require.NoError(t, err)
+	assert.Len(t, children, 2)
+
+	tree, err := taskService.GetTaskTree(ctx, user.ID, parent.ID)
+	require.NoError(t, err)
+	assert.Len(t, tree.Children, 2)
+	assert.InDelta(t, 100.0/3, tree.Progress, 0.01)
+
+	// Nesting a task under its own descendant is refused
+	parent.ParentID = &grandchild.ID
+	err = taskService.UpdateTask(ctx, user.ID, parent)
+	assert.ErrorIs(t, err, service.ErrTaskCycle)
+
+	// Completing a parent with open subtasks requires force
+	err = taskService.UpdateTaskStatus(ctx, user.ID, parent.ID, nil, model.TaskStatusCompleted, "", false)
+	assert.ErrorIs(t, err, service.ErrOpenSubtasks)
+	err = taskService.UpdateTaskStatus(ctx, user.ID, parent.ID, nil, model.TaskStatusCompleted, "", true)
+	assert.NoError(t, err)
+}
+
+func TestTaskService_Dependencies(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	// build -> test -> deploy
+	build := &model.Task{Title: "Build", UserID: user.ID, Status: model.TaskStatusPending}
+	require.NoError(t, taskService.CreateTask(ctx, build))
+	test := &model.Task{Title: "Test", UserID: user.ID, Status: model.TaskStatusPending}
+	require.NoError(t, taskService.CreateTask(ctx, test))
+	deploy := &model.Task{Title: "Deploy", UserID: user.ID, Status: model.TaskStatusPending}
+	require.NoError(t, taskService.CreateTask(ctx, deploy))
+
+	require.NoError(t, taskService.AddDependency(ctx, user.ID, test.ID, build.ID))
+	require.NoError(t, taskService.AddDependency(ctx, user.ID, deploy.ID, test.ID))
+
+	err = taskService.AddDependency(ctx, user.ID, deploy.ID, test.ID)
+	assert.ErrorIs(t, err, service.ErrDependencyExists)
+
+	// build -> deploy would close the cycle
+	err = taskService.AddDependency(ctx, user.ID, build.ID, deploy.ID)
+	assert.ErrorIs(t, err, service.ErrDependencyCycle)
+	err = taskService.AddDependency(ctx, user.ID, build.ID, build.ID)
+	assert.ErrorIs(t, err, service.ErrDependencyCycle)
+
+	found, err := taskService.GetTaskByID(ctx, user.ID, test.ID)
+	require.NoError(t, err)
+	assert.True(t, found.Blocked)
+
+	// A blocked task cannot be started, even with force
+	err = taskService.UpdateTaskStatus(ctx, user.ID, test.ID, nil, model.TaskStatusInProgress, "", true)
+	assert.ErrorIs(t, err, service.ErrTaskBlocked)
+
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, user.ID, build.ID, nil, model.TaskStatusCompleted, "", false))
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, user.ID, test.ID, nil, model.TaskStatusInProgress, "", false))
+
+	found, err = taskService.GetTaskByID(ctx, user.ID, test.ID)
+	require.NoError(t, err)
+	assert.False(t, found.Blocked)
+
+	require.NoError(t, taskService.RemoveDependency(ctx, user.ID, deploy.ID, test.ID))
+	err = taskService.RemoveDependency(ctx, user.ID, deploy.ID, test.ID)
+	assert.ErrorIs(t, err, service.ErrDependencyNotFound)
+}
+
+func TestTaskService_RecurringTask(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	// A recurring task needs a valid rule and a due date
+	err = taskService.CreateTask(ctx, &model.Task{Title: "Review", UserID: user.ID, Recurrence: "FREQ=WEEKLY"})
+	assert.ErrorIs(t, err, service.ErrInvalidRecurrence)
+	dueDate := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	err = taskService.CreateTask(ctx, &model.Task{Title: "Review", UserID: user.ID, DueDate: &dueDate, Recurrence: "FREQ=SOMETIMES"})
+	assert.ErrorIs(t, err, service.ErrInvalidRecurrence)
+
+	task := &model.Task{Title: "Review", UserID: user.ID, DueDate: &dueDate, Recurrence: "FREQ=WEEKLY;COUNT=2"}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, user.ID, task.ID, nil, model.TaskStatusCompleted, "", false))
+
+	pending, err := taskService.FindTasks(ctx, user.ID, model.TaskFilter{Statuses: []model.TaskStatus{model.TaskStatusPending}}, model.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, pending.Items, 1)
+	next := pending.Items[0]
+	assert.Equal(t, "Review", next.Title)
+	assert.Equal(t, task.ID, *next.SeriesID)
+	assert.Equal(t, 2, next.Occurrence)
+	assert.True(t, dueDate.AddDate(0, 0, 7).Equal(*next.DueDate))
+
+	// Reopening and completing again does not duplicate the next occurrence
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, user.ID, task.ID, nil, model.TaskStatusPending, "", false))
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, user.ID, task.ID, nil, model.TaskStatusCompleted, "", false))
+
+	// The series ends after COUNT occurrences
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, user.ID, next.ID, nil, model.TaskStatusCompleted, "", false))
+
+	tasks, err := taskService.GetTasksByUserID(ctx, user.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestTaskService_StatusTransitions(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	task := &model.Task{Title: "Task", UserID: user.ID}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+	assert.Equal(t, model.TaskStatusPending, task.Status)
+
+	err = taskService.UpdateTaskStatus(ctx, user.ID, task.ID, nil, model.TaskStatus("foo"), "", false)
+	assert.ErrorIs(t, err, service.ErrInvalidStatus)
+
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, user.ID, task.ID, nil, model.TaskStatusCancelled, "no longer needed", false))
+
+	// A cancelled task must be reopened before it is started again
+	err = taskService.UpdateTaskStatus(ctx, user.ID, task.ID, nil, model.TaskStatusInProgress, "", false)
+	assert.ErrorIs(t, err, service.ErrInvalidTransition)
+	task.Status = model.TaskStatusInProgress
+	err = taskService.UpdateTask(ctx, user.ID, task)
+	assert.ErrorIs(t, err, service.ErrInvalidTransition)
+
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, user.ID, task.ID, nil, model.TaskStatusPending, "", false))
+
+	history, err := taskService.GetStatusHistory(ctx, user.ID, task.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, model.TaskStatusPending, history[0].FromStatus)
+	assert.Equal(t, model.TaskStatusCancelled, history[0].ToStatus)
+	assert.Equal(t, "no longer needed", history[0].Reason)
+	assert.Equal(t, user.ID, history[0].UserID)
+	assert.Equal(t, model.TaskStatusPending, history[1].ToStatus)
+}
+
+func TestCommentService_Comments(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	commentService := service.NewCommentService(repository.NewCommentRepository(db), taskRepo, workspaceRepo)
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, owner))
+	alice := &model.User{Username: "alice", Email: "alice@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, alice))
+	bob := &model.User{Username: "bob", Email: "bob@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, bob))
+	outsider := &model.User{Username: "outsider", Email: "outsider@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, outsider))
+	for _, member := range []*model.User{alice, bob} {
+		require.NoError(t, workspaceRepo.SaveMembership(ctx, &model.Membership{WorkspaceID: owner.ID, UserID: member.ID, Role: model.WorkspaceRoleMember}))
+	}
+
+	task := &model.Task{Title: "Task", UserID: owner.ID}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+
+	// Unknown names, users outside the workspace, repeats and e-mail
+	// addresses are not mentions
+	comment := &model.Comment{
+		TaskID: task.ID,
+		UserID: owner.ID,
+		Body:   "@alice please check with @nobody, @outsider and @alice. Mail bob@example.com",
+	}
+	require.NoError(t, commentService.CreateComment(ctx, comment))
+	require.Len(t, comment.Mentions, 1)
+	assert.Equal(t, alice.ID, comment.Mentions[0].ID)
+	assert.Equal(t, "alice", comment.Mentions[0].Username)
+	assert.Equal(t, "owner", comment.User.Username)
+
+	err := commentService.CreateComment(ctx, &model.Comment{TaskID: task.ID, UserID: alice.ID, Body: "hi"})
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+	err = commentService.CreateComment(ctx, &model.Comment{TaskID: task.ID, UserID: owner.ID, Body: "   "})
+	assert.ErrorIs(t, err, service.ErrInvalidComment)
+
+	updated, err := commentService.UpdateComment(ctx, owner.ID, task.ID, comment.ID, "@bob please check")
+	require.NoError(t, err)
+	assert.NotNil(t, updated.EditedAt)
+	require.Len(t, updated.Mentions, 1)
+	assert.Equal(t, bob.ID, updated.Mentions[0].ID)
+
+	history, err := commentService.GetCommentHistory(ctx, owner.ID, task.ID, comment.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "@alice please check with @nobody, @outsider and @alice. Mail bob@example.com", history[0].Body)
+
+	comments, err := commentService.GetComments(ctx, owner.ID, task.ID, model.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, comments.Items, 1)
+	assert.Equal(t, "@bob please check", comments.Items[0].Body)
+
+	require.NoError(t, commentService.DeleteComment(ctx, owner.ID, task.ID, comment.ID))
+	comments, err = commentService.GetComments(ctx, owner.ID, task.ID, model.PageRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, comments.Items)
+}
+
 func TestCategoryService_CreateCategory(t *testing.T) {
 	db := setupTestDB()
 	if db == nil {
@@ -340,8 +682,8 @@ func TestCategoryService_CreateCategory(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -351,7 +693,7 @@ func TestCategoryService_CreateCategory(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test category
@@ -362,7 +704,7 @@ func TestCategoryService_CreateCategory(t *testing.T) {
 		UserID:      user.ID,
 	}
 
-	err = categoryService.CreateCategory(category)
+	err = categoryService.CreateCategory(ctx, category)
 
 	require.NoError(t, err)
 	assert.Equal(t, "Work Projects", category.Name)
@@ -378,8 +720,8 @@ func TestCategoryService_CreateCategory_EmptyName(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -389,7 +731,7 @@ func TestCategoryService_CreateCategory_EmptyName(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Try to create category with empty name
@@ -399,7 +741,7 @@ func TestCategoryService_CreateCategory_EmptyName(t *testing.T) {
 		UserID:      user.ID,
 	}
 
-	err = categoryService.CreateCategory(category)
+	err = categoryService.CreateCategory(ctx, category)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "category name is required")
@@ -412,8 +754,8 @@ func TestCategoryService_GetCategoryByID(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -423,7 +765,7 @@ func TestCategoryService_GetCategoryByID(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test category
@@ -433,11 +775,11 @@ func TestCategoryService_GetCategoryByID(t *testing.T) {
 		Color:       "#4CAF50",
 		UserID:      user.ID,
 	}
-	err = categoryService.CreateCategory(category)
+	err = categoryService.CreateCategory(ctx, category)
 	require.NoError(t, err)
 
 	// Get category by ID
-	foundCategory, err := categoryService.GetCategoryByID(category.ID)
+	foundCategory, err := categoryService.GetCategoryByID(ctx, user.ID, category.ID)
 
 	require.NoError(t, err)
 	assert.Equal(t, category.ID, foundCategory.ID)
@@ -452,8 +794,8 @@ func TestCategoryService_GetCategoriesByUserID(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -463,7 +805,7 @@ func TestCategoryService_GetCategoriesByUserID(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create multiple categories
@@ -482,12 +824,12 @@ func TestCategoryService_GetCategoriesByUserID(t *testing.T) {
 			Color:  data.color,
 			UserID: user.ID,
 		}
-		err := categoryService.CreateCategory(category)
+		err := categoryService.CreateCategory(ctx, category)
 		require.NoError(t, err)
 	}
 
 	// Get user categories
-	categories, err := categoryService.GetCategoriesByUserID(user.ID)
+	categories, err := categoryService.GetCategoriesByUserID(ctx, user.ID)
 
 	require.NoError(t, err)
 	assert.Len(t, categories, 3)
@@ -503,8 +845,8 @@ func TestCategoryService_UpdateCategory(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -514,7 +856,7 @@ func TestCategoryService_UpdateCategory(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test category
@@ -524,14 +866,14 @@ func TestCategoryService_UpdateCategory(t *testing.T) {
 		Color:       "#000000",
 		UserID:      user.ID,
 	}
-	err = categoryService.CreateCategory(category)
+	err = categoryService.CreateCategory(ctx, category)
 	require.NoError(t, err)
 
 	// Update category
 	category.Name = "Updated Category"
 	category.Description = "Updated description"
 	category.Color = "#E91E63"
-	err = categoryService.UpdateCategory(category)
+	err = categoryService.UpdateCategory(ctx, user.ID, category)
 
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Category", category.Name)
@@ -546,8 +888,8 @@ func TestCategoryService_DeleteCategory(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 
 	// Create test user
 	user := &model.User{
@@ -557,7 +899,7 @@ func TestCategoryService_DeleteCategory(t *testing.T) {
 		FirstName: "Test",
 		LastName:  "User",
 	}
-	err := userService.CreateUser(user)
+	err := userService.CreateUser(ctx, user)
 	require.NoError(t, err)
 
 	// Create test category
@@ -567,19 +909,151 @@ func TestCategoryService_DeleteCategory(t *testing.T) {
 		Color:       "#795548",
 		UserID:      user.ID,
 	}
-	err = categoryService.CreateCategory(category)
+	err = categoryService.CreateCategory(ctx, category)
 	require.NoError(t, err)
 
 	// Delete category
-	err = categoryService.DeleteCategory(category.ID)
+	err = categoryService.DeleteCategory(ctx, user.ID, category.ID, nil, "", nil)
 	require.NoError(t, err)
 
 	// Verify category is deleted
-	foundCategory, err := categoryService.GetCategoryByID(category.ID)
+	foundCategory, err := categoryService.GetCategoryByID(ctx, user.ID, category.ID)
 	assert.Error(t, err)
 	assert.Nil(t, foundCategory)
 }
 
+func TestCategoryService_DeletePolicies(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	taskService := newTestTaskService(taskRepo, db)
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db), repository.NewTxManager(db))
+
+	user := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	newCategory := func(name string) *model.Category {
+		category := &model.Category{Name: name, UserID: user.ID}
+		require.NoError(t, categoryService.CreateCategory(ctx, category))
+		return category
+	}
+	newTask := func(category *model.Category) *model.Task {
+		task := &model.Task{Title: "Task", UserID: user.ID, CategoryID: &category.ID}
+		require.NoError(t, taskService.CreateTask(ctx, task))
+		return task
+	}
+
+	// Reassigning needs another category as target
+	work, home := newCategory("Work"), newCategory("Home")
+	task := newTask(work)
+	assert.ErrorIs(t, categoryService.DeleteCategory(ctx, user.ID, work.ID, nil, model.CategoryDeleteReassign, nil), service.ErrInvalidDeletePolicy)
+	assert.ErrorIs(t, categoryService.DeleteCategory(ctx, user.ID, work.ID, nil, model.CategoryDeleteReassign, &work.ID), service.ErrInvalidDeletePolicy)
+	assert.ErrorIs(t, categoryService.DeleteCategory(ctx, user.ID, work.ID, nil, "archive", nil), service.ErrInvalidDeletePolicy)
+
+	require.NoError(t, categoryService.DeleteCategory(ctx, user.ID, work.ID, nil, model.CategoryDeleteReassign, &home.ID))
+	found, err := taskService.GetTaskByID(ctx, user.ID, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.CategoryID)
+	assert.Equal(t, home.ID, *found.CategoryID)
+
+	// Detaching keeps the tasks without a category
+	require.NoError(t, categoryService.DeleteCategory(ctx, user.ID, home.ID, nil, model.CategoryDeleteDetach, nil))
+	found, err = taskService.GetTaskByID(ctx, user.ID, task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.CategoryID)
+
+	// Deleting takes the tasks down with the category
+	errands := newCategory("Errands")
+	task = newTask(errands)
+	require.NoError(t, categoryService.DeleteCategory(ctx, user.ID, errands.ID, nil, model.CategoryDeleteTasks, nil))
+	_, err = taskService.GetTaskByID(ctx, user.ID, task.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+}
+
+func TestUserService_DeletePolicies(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(repository.NewTaskRepository(db), db)
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db), repository.NewTxManager(db))
+
+	authService := newTestAuthService(userService, db)
+
+	user := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, user))
+	category := &model.Category{Name: "Work", UserID: user.ID}
+	require.NoError(t, categoryService.CreateCategory(ctx, category))
+	task := &model.Task{Title: "Task", UserID: user.ID, CategoryID: &category.ID}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+	tokens, err := authService.Login(ctx, "owner", "password123")
+	require.NoError(t, err)
+
+	// The default policy refuses while the user still owns data
+	assert.ErrorIs(t, userService.DeleteUser(ctx, user.ID, user.ID, nil, ""), service.ErrUserHasContent)
+	assert.ErrorIs(t, userService.DeleteUser(ctx, user.ID, user.ID, nil, "purge"), service.ErrInvalidDeletePolicy)
+
+	require.NoError(t, userService.DeleteUser(ctx, user.ID, user.ID, nil, model.UserDeleteCascade))
+	_, err = userRepo.GetByID(ctx, user.ID)
+	assert.Error(t, err)
+
+	// The deleted user's sessions end with the account
+	var active int64
+	require.NoError(t, db.Model(&model.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&active).Error)
+	assert.Zero(t, active)
+	_, err = authService.ValidateAccessToken(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+	_, err = taskService.GetTaskByID(ctx, user.ID, task.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+	_, err = categoryService.GetCategoryByID(ctx, user.ID, category.ID)
+	assert.Error(t, err)
+}
+
+func TestUserService_DeleteUserWorkspaces(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, owner))
+	member := &model.User{Username: "member", Email: "member@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, member))
+
+	team := &model.Workspace{Name: "Team"}
+	require.NoError(t, workspaceRepo.Create(ctx, team, owner.ID))
+	require.NoError(t, workspaceRepo.SaveMembership(ctx, &model.Membership{WorkspaceID: team.ID, UserID: member.ID, Role: model.WorkspaceRoleMember}))
+	invitation := &model.Invitation{WorkspaceID: team.ID, Email: "new@example.com", Role: model.WorkspaceRoleMember,
+		TokenHash: "hash", InvitedByID: owner.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, invitationRepo.Create(ctx, invitation))
+
+	// The only owner of a team workspace cannot leave it without an owner
+	assert.ErrorIs(t, userService.DeleteUser(ctx, owner.ID, owner.ID, nil, ""), service.ErrSoleWorkspaceOwner)
+	require.NoError(t, workspaceRepo.SaveMembership(ctx, &model.Membership{WorkspaceID: team.ID, UserID: member.ID, Role: model.WorkspaceRoleOwner}))
+
+	require.NoError(t, userService.DeleteUser(ctx, owner.ID, owner.ID, nil, ""))
+	_, err := workspaceRepo.GetByID(ctx, owner.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = workspaceRepo.GetMembership(ctx, team.ID, owner.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = invitationRepo.GetByTokenHash(ctx, "hash")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	members, err := workspaceRepo.GetMembers(ctx, team.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, member.ID, members[0].UserID)
+}
+
 func TestCategoryService_ListCategories(t *testing.T) {
 	db := setupTestDB()
 	if db == nil {
@@ -587,8 +1061,8 @@ func TestCategoryService_ListCategories(t *testing.T) {
 	}
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewTxManager(db))
 
 	// Create test users
 	user1 := &model.User{
@@ -598,7 +1072,7 @@ func TestCategoryService_ListCategories(t *testing.T) {
 		FirstName: "User",
 		LastName:  "One",
 	}
-	err := userService.CreateUser(user1)
+	err := userService.CreateUser(ctx, user1)
 	require.NoError(t, err)
 
 	user2 := &model.User{
@@ -608,7 +1082,7 @@ func TestCategoryService_ListCategories(t *testing.T) {
 		FirstName: "User",
 		LastName:  "Two",
 	}
-	err = userService.CreateUser(user2)
+	err = userService.CreateUser(ctx, user2)
 	require.NoError(t, err)
 
 	// Create categories for both users
@@ -620,22 +1094,874 @@ func TestCategoryService_ListCategories(t *testing.T) {
 			Name:   name,
 			UserID: users[i].ID,
 		}
-		err := categoryService.CreateCategory(category)
+		err := categoryService.CreateCategory(ctx, category)
 		require.NoError(t, err)
 	}
 
 	// Test List with different pagination
-	allCategories, err := categoryService.ListCategories(10, 0)
+	allCategories, err := categoryService.ListCategories(ctx, model.PageRequest{Limit: 10, WithTotal: true})
 	require.NoError(t, err)
-	assert.Len(t, allCategories, 5)
+	assert.Len(t, allCategories.Items, 5)
+	assert.False(t, allCategories.HasMore)
+	assert.Equal(t, int64(5), *allCategories.Total)
 
 	// Test with limit
-	limitedCategories, err := categoryService.ListCategories(3, 0)
+	limitedCategories, err := categoryService.ListCategories(ctx, model.PageRequest{Limit: 3})
+	require.NoError(t, err)
+	assert.Len(t, limitedCategories.Items, 3)
+	assert.True(t, limitedCategories.HasMore)
+	assert.Nil(t, limitedCategories.Total)
+
+	// Test with cursor
+	nextCategories, err := categoryService.ListCategories(ctx, model.PageRequest{Limit: 3, Cursor: limitedCategories.NextCursor})
+	require.NoError(t, err)
+	assert.Len(t, nextCategories.Items, 2) // 5 total - 3 on the first page = 2
+	assert.False(t, nextCategories.HasMore)
+	assert.Equal(t, "Travel", nextCategories.Items[0].Name)
+
+	_, err = categoryService.ListCategories(ctx, model.PageRequest{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+}
+
+func newTestTaskService(taskRepo repository.TaskRepository, db *gorm.DB) service.TaskService {
+	return service.NewTaskService(taskRepo, repository.NewCategoryRepository(db), repository.NewProjectRepository(db),
+		repository.NewBoardRepository(db), repository.NewTaskDependencyRepository(db), repository.NewTaskStatusChangeRepository(db),
+		repository.NewTaskCollaboratorRepository(db), repository.NewTxManager(db), configs.TaskConfig{})
+}
+
+func newTestAuthService(userService service.UserService, db *gorm.DB) service.AuthService {
+	return service.NewAuthService(userService, repository.NewRefreshTokenRepository(db), configs.SecurityConfig{
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+}
+
+func TestAuthService_Login(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userService := service.NewUserService(repository.NewUserRepository(db), repository.NewTxManager(db))
+	authService := newTestAuthService(userService, db)
+
+	user := &model.User{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	// Login by username and by email
+	for _, identifier := range []string{"testuser", "test@example.com"} {
+		tokens, err := authService.Login(ctx, identifier, "password123")
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+
+		userID, err := authService.ValidateAccessToken(ctx, tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+	}
+
+	// Wrong password
+	_, err = authService.Login(ctx, "testuser", "wrongpassword")
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userService := service.NewUserService(repository.NewUserRepository(db), repository.NewTxManager(db))
+	authService := newTestAuthService(userService, db)
+
+	user := &model.User{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	first, err := authService.Login(ctx, "testuser", "password123")
 	require.NoError(t, err)
-	assert.Len(t, limitedCategories, 3)
 
-	// Test with offset
-	offsetCategories, err := categoryService.ListCategories(10, 2)
+	// Refresh rotates the token
+	second, err := authService.Refresh(ctx, first.RefreshToken)
 	require.NoError(t, err)
-	assert.Len(t, offsetCategories, 3) // 5 total - 2 offset = 3
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// Reusing the rotated token fails and revokes the whole family
+	_, err = authService.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+
+	_, err = authService.Refresh(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userService := service.NewUserService(repository.NewUserRepository(db), repository.NewTxManager(db))
+	authService := newTestAuthService(userService, db)
+
+	user := &model.User{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	tokens, err := authService.Login(ctx, "testuser", "password123")
+	require.NoError(t, err)
+
+	err = authService.Logout(ctx, tokens.RefreshToken, false)
+	require.NoError(t, err)
+
+	_, err = authService.Refresh(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+}
+
+func TestRoleService_CreateRole_Validation(t *testing.T) {
+	roleService := service.NewRoleService(nil, nil)
+
+	err := roleService.CreateRole(ctx, &model.Role{Name: model.RoleAdmin})
+	assert.EqualError(t, err, "role name is reserved")
+
+	err = roleService.CreateRole(ctx, &model.Role{
+		Name:        "auditor",
+		Permissions: []model.Permission{"tasks:destroy_everything"},
+	})
+	assert.ErrorIs(t, err, service.ErrUnknownPermission)
+}
+
+func TestRoleService_HasPermission(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	roleService := service.NewRoleService(repository.NewRoleRepository(db), userRepo)
+
+	user := &model.User{Username: "testuser", Email: "test@example.com", Password: "password123"}
+	err := userService.CreateUser(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, model.RoleUser, user.Role)
+
+	// Ordinary users have no admin permissions
+	allowed, err := roleService.HasPermission(ctx, user.ID, model.PermissionUsersList)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Custom role grants exactly its permissions
+	err = roleService.CreateRole(ctx, &model.Role{
+		Name:        "auditor",
+		Permissions: []model.Permission{model.PermissionTasksListAll},
+	})
+	require.NoError(t, err)
+	err = roleService.AssignRole(ctx, user.ID, "auditor")
+	require.NoError(t, err)
+
+	allowed, err = roleService.HasPermission(ctx, user.ID, model.PermissionTasksListAll)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = roleService.HasPermission(ctx, user.ID, model.PermissionUsersList)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Admins have every permission
+	err = roleService.AssignRole(ctx, user.ID, model.RoleAdmin)
+	require.NoError(t, err)
+	for _, permission := range model.AllPermissions {
+		allowed, err = roleService.HasPermission(ctx, user.ID, permission)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	// The last admin cannot be demoted
+	err = roleService.AssignRole(ctx, user.ID, model.RoleUser)
+	assert.ErrorIs(t, err, service.ErrLastAdmin)
+}
+
+func TestAttachmentService_Attachments(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	attachmentService := service.NewAttachmentService(repository.NewAttachmentRepository(db), taskRepo, store, configs.AttachmentConfig{
+		MaxSize:      16,
+		AllowedTypes: []string{"text/*"},
+	})
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, owner))
+	other := &model.User{Username: "other", Email: "other@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, other))
+
+	task := &model.Task{Title: "Task", UserID: owner.ID}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+
+	upload := func(name, content, contentType string) (*model.Attachment, error) {
+		return attachmentService.UploadAttachment(ctx, owner.ID, task.ID, service.AttachmentUpload{
+			FileName:    name,
+			Size:        int64(len(content)),
+			ContentType: contentType,
+			Content:     strings.NewReader(content),
+		})
+	}
+
+	// Directories are dropped and the declared type refines plain text
+	attachment, err := upload("../notes/data.csv", "a,b\n1,2\n", "text/csv")
+	require.NoError(t, err)
+	assert.Equal(t, "data.csv", attachment.FileName)
+	assert.Equal(t, "text/csv", attachment.ContentType)
+	sum := sha256.Sum256([]byte("a,b\n1,2\n"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), attachment.Checksum)
+
+	_, err = upload("big.txt", strings.Repeat("x", 17), "text/plain")
+	assert.ErrorIs(t, err, service.ErrAttachmentTooLarge)
+	// Sniffed contents win over the declared type
+	_, err = upload("image.txt", "\x89PNG\r\n\x1a\n0000", "text/plain")
+	assert.ErrorIs(t, err, service.ErrAttachmentTypeNotAllowed)
+	_, err = upload("empty.txt", "", "text/plain")
+	assert.ErrorIs(t, err, service.ErrInvalidAttachment)
+
+	_, err = attachmentService.GetAttachments(ctx, other.ID, task.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+
+	attachments, err := attachmentService.GetAttachments(ctx, owner.ID, task.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+
+	found, content, err := attachmentService.OpenAttachment(ctx, owner.ID, task.ID, attachment.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	content.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", string(data))
+	assert.Equal(t, attachment.Checksum, found.Checksum)
+
+	require.NoError(t, attachmentService.DeleteAttachment(ctx, owner.ID, task.ID, attachment.ID))
+	_, _, err = attachmentService.OpenAttachment(ctx, owner.ID, task.ID, attachment.ID)
+	assert.ErrorIs(t, err, service.ErrAttachmentNotFound)
+}
+
+func TestTagService_Tags(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+	tagService := service.NewTagService(repository.NewTagRepository(db), taskRepo)
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, owner))
+	other := &model.User{Username: "other", Email: "other@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, other))
+
+	task := &model.Task{Title: "Task", UserID: owner.ID}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+
+	bug := &model.Tag{Name: " bug ", UserID: owner.ID}
+	require.NoError(t, tagService.CreateTag(ctx, bug))
+	assert.Equal(t, "bug", bug.Name)
+	assert.ErrorIs(t, tagService.CreateTag(ctx, &model.Tag{Name: "BUG", UserID: owner.ID}), service.ErrTagExists)
+	assert.ErrorIs(t, tagService.CreateTag(ctx, &model.Tag{Name: "  ", UserID: owner.ID}), service.ErrInvalidTag)
+	// Names are only unique per user
+	foreign := &model.Tag{Name: "bug", UserID: other.ID}
+	require.NoError(t, tagService.CreateTag(ctx, foreign))
+
+	defect := &model.Tag{Name: "defect", UserID: owner.ID}
+	require.NoError(t, tagService.CreateTag(ctx, defect))
+
+	// Renaming onto another tag's name is refused, changing case is not
+	err := tagService.UpdateTag(ctx, owner.ID, &model.Tag{ID: defect.ID, Name: "Bug"})
+	assert.ErrorIs(t, err, service.ErrTagExists)
+	require.NoError(t, tagService.UpdateTag(ctx, owner.ID, &model.Tag{ID: bug.ID, Name: "Bug", Color: "#f00"}))
+
+	assert.ErrorIs(t, tagService.AddTagsToTask(ctx, owner.ID, task.ID, []uuid.UUID{foreign.ID}), service.ErrTagNotFound)
+	assert.ErrorIs(t, tagService.AddTagsToTask(ctx, other.ID, task.ID, []uuid.UUID{foreign.ID}), service.ErrTaskNotFound)
+	require.NoError(t, tagService.AddTagsToTask(ctx, owner.ID, task.ID, []uuid.UUID{bug.ID, defect.ID}))
+
+	found, err := taskService.GetTaskByID(ctx, owner.ID, task.ID)
+	require.NoError(t, err)
+	assert.Len(t, found.Tags, 2)
+
+	_, err = tagService.MergeTags(ctx, owner.ID, defect.ID, defect.ID)
+	assert.ErrorIs(t, err, service.ErrInvalidTag)
+	_, err = tagService.MergeTags(ctx, owner.ID, defect.ID, foreign.ID)
+	assert.ErrorIs(t, err, service.ErrTagNotFound)
+	target, err := tagService.MergeTags(ctx, owner.ID, defect.ID, bug.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bug", target.Name)
+
+	tags, err := tagService.GetTagsByUserID(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.Equal(t, int64(1), *tags[0].UsageCount)
+
+	require.NoError(t, tagService.RemoveTagFromTask(ctx, owner.ID, task.ID, bug.ID))
+	assert.ErrorIs(t, tagService.RemoveTagFromTask(ctx, owner.ID, task.ID, bug.ID), service.ErrTagNotFound)
+	require.NoError(t, tagService.DeleteTag(ctx, owner.ID, bug.ID))
+	assert.ErrorIs(t, tagService.DeleteTag(ctx, owner.ID, foreign.ID), service.ErrTagNotFound)
+}
+
+func TestCollaboratorService_SharingAndAssignment(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	taskService := newTestTaskService(taskRepo, db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	collaboratorService := service.NewCollaboratorService(repository.NewTaskCollaboratorRepository(db), taskRepo, userRepo, workspaceRepo)
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, owner))
+	viewer := &model.User{Username: "viewer", Email: "viewer@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, viewer))
+	assignee := &model.User{Username: "assignee", Email: "assignee@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, assignee))
+	stranger := &model.User{Username: "stranger", Email: "stranger@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, stranger))
+
+	// Collaborators join the owner's personal workspace as guests
+	for _, guest := range []*model.User{viewer, assignee} {
+		require.NoError(t, workspaceRepo.SaveMembership(ctx, &model.Membership{WorkspaceID: owner.ID, UserID: guest.ID, Role: model.WorkspaceRoleGuest}))
+	}
+
+	task := &model.Task{Title: "Task", UserID: owner.ID}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+
+	_, err := collaboratorService.ShareTask(ctx, owner.ID, task.ID, stranger.ID, model.TaskPermissionView)
+	assert.ErrorIs(t, err, service.ErrInvalidShare)
+	_, err = collaboratorService.ShareTask(ctx, owner.ID, task.ID, viewer.ID, model.TaskPermissionOwner)
+	assert.ErrorIs(t, err, service.ErrInvalidShare)
+	_, err = collaboratorService.ShareTask(ctx, owner.ID, task.ID, owner.ID, model.TaskPermissionView)
+	assert.ErrorIs(t, err, service.ErrInvalidShare)
+	share, err := collaboratorService.ShareTask(ctx, owner.ID, task.ID, viewer.ID, model.TaskPermissionView)
+	require.NoError(t, err)
+	assert.Equal(t, "viewer", share.User.Username)
+
+	// Viewers can read but not change the task or its collaborators
+	_, err = taskService.GetTaskByID(ctx, viewer.ID, task.ID)
+	require.NoError(t, err)
+	err = taskService.UpdateTaskStatus(ctx, viewer.ID, task.ID, nil, model.TaskStatusInProgress, "", false)
+	assert.ErrorIs(t, err, service.ErrTaskForbidden)
+	assert.ErrorIs(t, collaboratorService.AssignTask(ctx, viewer.ID, task.ID, viewer.ID), service.ErrTaskForbidden)
+	_, err = collaboratorService.ShareTask(ctx, viewer.ID, task.ID, stranger.ID, model.TaskPermissionView)
+	assert.ErrorIs(t, err, service.ErrTaskForbidden)
+
+	// Strangers do not learn that the task exists
+	_, err = taskService.GetTaskByID(ctx, stranger.ID, task.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+
+	// Assignees can edit, but only the owner can delete
+	require.NoError(t, collaboratorService.AssignTask(ctx, owner.ID, task.ID, assignee.ID))
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, assignee.ID, task.ID, nil, model.TaskStatusInProgress, "", false))
+	assert.ErrorIs(t, taskService.DeleteTask(ctx, assignee.ID, task.ID, nil), service.ErrTaskForbidden)
+
+	history, err := taskService.GetStatusHistory(ctx, viewer.ID, task.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, assignee.ID, history[0].UserID)
+
+	assigned, err := taskService.FindAssignedTasks(ctx, assignee.ID, model.TaskFilter{}, model.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, assigned.Items, 1)
+	assert.Equal(t, task.ID, assigned.Items[0].ID)
+	assigned, err = taskService.FindAssignedTasks(ctx, viewer.ID, model.TaskFilter{}, model.PageRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, assigned.Items)
+
+	// Upgrading the share lets the viewer edit
+	_, err = collaboratorService.ShareTask(ctx, owner.ID, task.ID, viewer.ID, model.TaskPermissionEdit)
+	require.NoError(t, err)
+	shares, err := collaboratorService.GetShares(ctx, assignee.ID, task.ID)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.Equal(t, model.TaskPermissionEdit, shares[0].Permission)
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, viewer.ID, task.ID, nil, model.TaskStatusCompleted, "", false))
+
+	require.NoError(t, collaboratorService.UnassignTask(ctx, owner.ID, task.ID, assignee.ID))
+	assert.ErrorIs(t, collaboratorService.UnassignTask(ctx, owner.ID, task.ID, assignee.ID), service.ErrAssigneeNotFound)
+	_, err = taskService.GetTaskByID(ctx, assignee.ID, task.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+
+	require.NoError(t, collaboratorService.UnshareTask(ctx, owner.ID, task.ID, viewer.ID))
+	assert.ErrorIs(t, collaboratorService.UnshareTask(ctx, owner.ID, task.ID, viewer.ID), service.ErrShareNotFound)
+}
+
+func TestWorkspaceService_MembersAndInvitations(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	userService := service.NewUserService(userRepo, repository.NewTxManager(db))
+	workspaceService := service.NewWorkspaceService(repository.NewWorkspaceRepository(db),
+		repository.NewInvitationRepository(db), userRepo, userService, repository.NewTxManager(db), configs.WorkspaceConfig{InvitationTTL: time.Hour})
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, owner))
+	member := &model.User{Username: "member", Email: "member@example.com", Password: "password123"}
+	require.NoError(t, userService.CreateUser(ctx, member))
+
+	workspace := &model.Workspace{Name: "  Team  "}
+	require.NoError(t, workspaceService.CreateWorkspace(ctx, owner.ID, workspace))
+	assert.Equal(t, "Team", workspace.Name)
+
+	// Non-members do not see the workspace
+	_, err := workspaceService.GetWorkspace(ctx, member.ID, workspace.ID)
+	assert.ErrorIs(t, err, service.ErrWorkspaceNotFound)
+
+	_, err = workspaceService.CreateInvitation(ctx, owner.ID, &model.Invitation{WorkspaceID: workspace.ID, Email: "member@example.com", Role: model.WorkspaceRoleOwner})
+	assert.ErrorIs(t, err, service.ErrInvalidInvitation)
+	token, err := workspaceService.CreateInvitation(ctx, owner.ID, &model.Invitation{WorkspaceID: workspace.ID, Email: "Member@Example.com", Role: model.WorkspaceRoleMember})
+	require.NoError(t, err)
+
+	// Only the invited address can use the invitation, and only once
+	_, err = workspaceService.AcceptInvitation(ctx, owner.ID, token)
+	assert.ErrorIs(t, err, service.ErrInvitationNotFound)
+	membership, err := workspaceService.AcceptInvitation(ctx, member.ID, token)
+	require.NoError(t, err)
+	assert.Equal(t, model.WorkspaceRoleMember, membership.Role)
+	_, err = workspaceService.AcceptInvitation(ctx, member.ID, token)
+	assert.ErrorIs(t, err, service.ErrInvitationExpired)
+
+	// New users register through an invitation
+	token, err = workspaceService.CreateInvitation(ctx, owner.ID, &model.Invitation{WorkspaceID: workspace.ID, Email: "guest@example.com", Role: model.WorkspaceRoleGuest})
+	require.NoError(t, err)
+	guest := &model.User{Username: "guest", Password: "password123"}
+	membership, err = workspaceService.RegisterWithInvitation(ctx, token, guest)
+	require.NoError(t, err)
+	assert.Equal(t, "guest@example.com", guest.Email)
+	assert.Equal(t, model.WorkspaceRoleGuest, membership.Role)
+
+	// A used invitation creates no further account
+	_, err = workspaceService.RegisterWithInvitation(ctx, token, &model.User{Username: "second", Password: "password123"})
+	assert.ErrorIs(t, err, service.ErrInvitationExpired)
+	_, err = userRepo.GetByUsername(ctx, "second")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	members, err := workspaceService.GetMembers(ctx, guest.ID, workspace.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 3)
+
+	// Members cannot manage, and only owners touch ownership
+	_, err = workspaceService.UpdateMemberRole(ctx, member.ID, workspace.ID, guest.ID, model.WorkspaceRoleMember)
+	assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+	_, err = workspaceService.UpdateMemberRole(ctx, owner.ID, workspace.ID, member.ID, model.WorkspaceRoleAdmin)
+	require.NoError(t, err)
+	_, err = workspaceService.UpdateMemberRole(ctx, member.ID, workspace.ID, owner.ID, model.WorkspaceRoleMember)
+	assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+	_, err = workspaceService.UpdateMemberRole(ctx, owner.ID, workspace.ID, owner.ID, model.WorkspaceRoleAdmin)
+	assert.ErrorIs(t, err, service.ErrLastOwner)
+	assert.ErrorIs(t, workspaceService.RemoveMember(ctx, owner.ID, workspace.ID, owner.ID), service.ErrLastOwner)
+
+	require.NoError(t, workspaceService.RemoveMember(ctx, guest.ID, workspace.ID, guest.ID))
+	assert.ErrorIs(t, workspaceService.RemoveMember(ctx, owner.ID, workspace.ID, guest.ID), service.ErrMemberNotFound)
+
+	assert.ErrorIs(t, workspaceService.DeleteWorkspace(ctx, member.ID, workspace.ID), service.ErrWorkspaceForbidden)
+	assert.ErrorIs(t, workspaceService.DeleteWorkspace(ctx, owner.ID, owner.ID), service.ErrPersonalWorkspace)
+	require.NoError(t, workspaceService.DeleteWorkspace(ctx, owner.ID, workspace.ID))
+	_, err = workspaceService.GetMembership(ctx, member.ID, workspace.ID)
+	assert.ErrorIs(t, err, service.ErrWorkspaceNotFound)
+}
+
+func TestTaskService_InWorkspace(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	taskService := newTestTaskService(taskRepo, db)
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db), repository.NewTxManager(db))
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, owner))
+	guest := &model.User{Username: "guest", Email: "guest@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, guest))
+
+	team := &model.Workspace{Name: "Team"}
+	require.NoError(t, workspaceRepo.Create(ctx, team, owner.ID))
+	ownerMembership := model.Membership{WorkspaceID: team.ID, UserID: owner.ID, Role: model.WorkspaceRoleOwner}
+	guestMembership := model.Membership{WorkspaceID: team.ID, UserID: guest.ID, Role: model.WorkspaceRoleGuest}
+
+	category := &model.Category{Name: "Team work", UserID: owner.ID}
+	require.NoError(t, categoryService.InWorkspace(ownerMembership).CreateCategory(ctx, category))
+	assert.Equal(t, team.ID, category.WorkspaceID)
+
+	// Tasks cannot use a category of another workspace
+	personal := &model.Task{Title: "Personal", UserID: owner.ID, CategoryID: &category.ID}
+	assert.ErrorIs(t, taskService.InWorkspace(model.Membership{WorkspaceID: owner.ID, UserID: owner.ID, Role: model.WorkspaceRoleOwner}).CreateTask(ctx, personal), service.ErrCategoryNotFound)
+
+	task := &model.Task{Title: "Team task", UserID: owner.ID, CategoryID: &category.ID}
+	require.NoError(t, taskService.InWorkspace(ownerMembership).CreateTask(ctx, task))
+	assert.Equal(t, team.ID, task.WorkspaceID)
+
+	// Guests cannot add tasks or categories
+	guestTask := &model.Task{Title: "Guest task", UserID: guest.ID}
+	assert.ErrorIs(t, taskService.InWorkspace(guestMembership).CreateTask(ctx, guestTask), service.ErrWorkspaceForbidden)
+	guestCategory := &model.Category{Name: "Guest category", UserID: guest.ID}
+	assert.ErrorIs(t, categoryService.InWorkspace(guestMembership).CreateCategory(ctx, guestCategory), service.ErrWorkspaceForbidden)
+
+	// The task is only found in its own workspace
+	_, err := taskService.InWorkspace(model.Membership{WorkspaceID: owner.ID, UserID: owner.ID, Role: model.WorkspaceRoleOwner}).GetTaskByID(ctx, owner.ID, task.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+	found, err := taskService.InWorkspace(ownerMembership).GetTaskByID(ctx, owner.ID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Team task", found.Title)
+}
+
+func TestTaskService_WorkspaceRoles(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	taskService := newTestTaskService(taskRepo, db)
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db), repository.NewTxManager(db))
+
+	memberships := map[model.WorkspaceRole]model.Membership{}
+	users := map[model.WorkspaceRole]*model.User{}
+	team := &model.Workspace{Name: "Team"}
+	for _, role := range []model.WorkspaceRole{model.WorkspaceRoleOwner, model.WorkspaceRoleAdmin, model.WorkspaceRoleMember, model.WorkspaceRoleGuest} {
+		user := &model.User{Username: string(role), Email: string(role) + "@example.com", Password: "password123"}
+		require.NoError(t, userRepo.Create(ctx, user))
+		if role == model.WorkspaceRoleOwner {
+			require.NoError(t, workspaceRepo.Create(ctx, team, user.ID))
+		}
+		membership := model.Membership{WorkspaceID: team.ID, UserID: user.ID, Role: role}
+		require.NoError(t, workspaceRepo.SaveMembership(ctx, &membership))
+		memberships[role], users[role] = membership, user
+	}
+	author := &model.User{Username: "author", Email: "author@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, author))
+	authorMembership := model.Membership{WorkspaceID: team.ID, UserID: author.ID, Role: model.WorkspaceRoleMember}
+	require.NoError(t, workspaceRepo.SaveMembership(ctx, &authorMembership))
+
+	category := &model.Category{Name: "Author's", UserID: author.ID}
+	require.NoError(t, categoryService.InWorkspace(authorMembership).CreateCategory(ctx, category))
+	task := &model.Task{Title: "Author's task", UserID: author.ID}
+	require.NoError(t, taskService.InWorkspace(authorMembership).CreateTask(ctx, task))
+
+	// Members see the tasks and categories of the workspace but cannot change
+	// those of others
+	member := memberships[model.WorkspaceRoleMember]
+	_, err := taskService.InWorkspace(member).GetTaskByID(ctx, member.UserID, task.ID)
+	require.NoError(t, err)
+	_, err = categoryService.InWorkspace(member).GetCategoryByID(ctx, member.UserID, category.ID)
+	require.NoError(t, err)
+	err = taskService.InWorkspace(member).UpdateTaskStatus(ctx, member.UserID, task.ID, nil, model.TaskStatusInProgress, "", false)
+	assert.ErrorIs(t, err, service.ErrTaskForbidden)
+	err = categoryService.InWorkspace(member).UpdateCategory(ctx, member.UserID, &model.Category{ID: category.ID, Name: "Renamed"})
+	assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+
+	// Guests only see what was shared with them
+	guest := memberships[model.WorkspaceRoleGuest]
+	_, err = taskService.InWorkspace(guest).GetTaskByID(ctx, guest.UserID, task.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+	_, err = categoryService.InWorkspace(guest).GetCategoryByID(ctx, guest.UserID, category.ID)
+	assert.ErrorIs(t, err, service.ErrCategoryNotFound)
+
+	// Owners and admins manage every task and category of the workspace
+	for _, role := range []model.WorkspaceRole{model.WorkspaceRoleOwner, model.WorkspaceRoleAdmin} {
+		manager := memberships[role]
+		require.NoError(t, taskService.InWorkspace(manager).UpdateTaskStatus(ctx, manager.UserID, task.ID, nil, model.TaskStatusInProgress, "", false), role)
+		require.NoError(t, taskService.InWorkspace(manager).UpdateTaskStatus(ctx, manager.UserID, task.ID, nil, model.TaskStatusPending, "", false), role)
+		require.NoError(t, categoryService.InWorkspace(manager).UpdateCategory(ctx, manager.UserID, &model.Category{ID: category.ID, Name: "Renamed by " + string(role)}), role)
+	}
+	admin := memberships[model.WorkspaceRoleAdmin]
+	require.NoError(t, taskService.InWorkspace(admin).DeleteTask(ctx, admin.UserID, task.ID, nil))
+
+	// Outside the workspace the role gives no access
+	other := &model.Task{Title: "Personal", UserID: author.ID}
+	require.NoError(t, taskService.CreateTask(ctx, other))
+	_, err = taskService.InWorkspace(admin).GetTaskByID(ctx, admin.UserID, other.ID)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+}
+
+func TestProjectService_Progress(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	taskService := newTestTaskService(taskRepo, db)
+	projectService := service.NewProjectService(repository.NewProjectRepository(db))
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, owner))
+	other := &model.User{Username: "other", Email: "other@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, other))
+
+	project := &model.Project{Name: "Launch", UserID: owner.ID}
+	require.NoError(t, projectService.CreateProject(ctx, project))
+	assert.Equal(t, model.ProjectStatusPlanned, project.Status)
+
+	start := time.Now()
+	before := start.Add(-24 * time.Hour)
+	invalid := &model.Project{Name: "Backwards", UserID: owner.ID, StartDate: &start, TargetDate: &before}
+	assert.ErrorIs(t, projectService.CreateProject(ctx, invalid), service.ErrInvalidProject)
+
+	milestone := &model.Milestone{ProjectID: project.ID, Name: "Beta"}
+	require.NoError(t, projectService.CreateMilestone(ctx, owner.ID, milestone))
+
+	// A milestone alone places the task in its project
+	done := &model.Task{Title: "Done", UserID: owner.ID, MilestoneID: &milestone.ID}
+	require.NoError(t, taskService.CreateTask(ctx, done))
+	require.NotNil(t, done.ProjectID)
+	assert.Equal(t, project.ID, *done.ProjectID)
+	require.NoError(t, taskService.UpdateTaskStatus(ctx, owner.ID, done.ID, nil, model.TaskStatusCompleted, "", false))
+
+	open := &model.Task{Title: "Open", UserID: owner.ID, ProjectID: &project.ID}
+	require.NoError(t, taskService.CreateTask(ctx, open))
+	cancelled := &model.Task{Title: "Dropped", Status: model.TaskStatusCancelled, UserID: owner.ID, ProjectID: &project.ID}
+	require.NoError(t, taskService.CreateTask(ctx, cancelled))
+
+	progress, err := projectService.GetProjectProgress(ctx, owner.ID, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), progress.Open)
+	assert.Equal(t, int64(1), progress.Completed)
+	assert.Equal(t, 50.0, progress.Percentage)
+	require.Len(t, progress.Milestones, 1)
+	assert.Equal(t, 100.0, progress.Milestones[0].Percentage)
+
+	// Other users cannot see the project or put tasks in it
+	_, err = projectService.GetProjectProgress(ctx, other.ID, project.ID)
+	assert.ErrorIs(t, err, service.ErrProjectNotFound)
+	foreign := &model.Task{Title: "Foreign", UserID: other.ID, ProjectID: &project.ID}
+	assert.ErrorIs(t, taskService.CreateTask(ctx, foreign), service.ErrProjectNotFound)
+}
+
+func TestTaskService_MoveTask(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	taskService := newTestTaskService(taskRepo, db)
+	boardService := service.NewBoardService(repository.NewBoardRepository(db))
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, owner))
+	other := &model.User{Username: "other", Email: "other@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, other))
+
+	done := model.TaskStatusCompleted
+	board := &model.Board{Name: "Sprint", UserID: owner.ID, Columns: []model.BoardColumn{
+		{Name: "Backlog"},
+		{Name: "Done", Status: &done},
+	}}
+	require.NoError(t, boardService.CreateBoard(ctx, board))
+	backlog, doneColumn := board.Columns[0], board.Columns[1]
+	assert.Less(t, backlog.Rank, doneColumn.Rank)
+
+	var tasks []*model.Task
+	for _, title := range []string{"A", "B", "C"} {
+		task := &model.Task{Title: title, UserID: owner.ID}
+		require.NoError(t, taskService.CreateTask(ctx, task))
+		tasks = append(tasks, task)
+	}
+
+	// A at the top, B after A, then C at the top again
+	_, err := taskService.MoveTask(ctx, owner.ID, tasks[0].ID, nil, backlog.ID, nil)
+	require.NoError(t, err)
+	_, err = taskService.MoveTask(ctx, owner.ID, tasks[1].ID, nil, backlog.ID, &tasks[0].ID)
+	require.NoError(t, err)
+	_, err = taskService.MoveTask(ctx, owner.ID, tasks[2].ID, nil, backlog.ID, nil)
+	require.NoError(t, err)
+
+	loaded, err := boardService.GetBoardByID(ctx, owner.ID, board.ID)
+	require.NoError(t, err)
+	var titles []string
+	for _, task := range loaded.Columns[0].Tasks {
+		titles = append(titles, task.Title)
+	}
+	assert.Equal(t, []string{"C", "A", "B"}, titles)
+
+	// Moving into a column with a status changes the task's status
+	moved, err := taskService.MoveTask(ctx, owner.ID, tasks[0].ID, nil, doneColumn.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, model.TaskStatusCompleted, moved.Status)
+	history, err := taskService.GetStatusHistory(ctx, owner.ID, tasks[0].ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	// The task to move after must be in the target column
+	_, err = taskService.MoveTask(ctx, owner.ID, tasks[1].ID, nil, doneColumn.ID, &tasks[2].ID)
+	assert.ErrorIs(t, err, repository.ErrInvalidPosition)
+
+	// Tasks cannot be moved onto another user's board
+	foreign := &model.Task{Title: "Foreign", UserID: other.ID}
+	require.NoError(t, taskService.CreateTask(ctx, foreign))
+	_, err = taskService.MoveTask(ctx, other.ID, foreign.ID, nil, backlog.ID, nil)
+	assert.ErrorIs(t, err, service.ErrColumnNotFound)
+}
+
+func TestTrashService_RestoreAndPurge(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	taskService := newTestTaskService(taskRepo, db)
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db), repository.NewTxManager(db))
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	trashService := service.NewTrashService(repository.NewTrashRepository(db), store, configs.TrashConfig{Retention: time.Hour})
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, owner))
+	other := &model.User{Username: "other", Email: "other@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, other))
+
+	category := &model.Category{Name: "Work", UserID: owner.ID}
+	require.NoError(t, categoryService.CreateCategory(ctx, category))
+	task := &model.Task{Title: "Task", UserID: owner.ID, CategoryID: &category.ID}
+	require.NoError(t, taskService.CreateTask(ctx, task))
+	require.NoError(t, taskService.DeleteTask(ctx, owner.ID, task.ID, nil))
+	require.NoError(t, categoryService.DeleteCategory(ctx, owner.ID, category.ID, nil, "", nil))
+
+	trash, err := trashService.GetTrash(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, trash.Tasks, 1)
+	require.Len(t, trash.Categories, 1)
+	require.NotNil(t, trash.Tasks[0].PurgeAt)
+
+	// Other users cannot touch the trash
+	assert.ErrorIs(t, trashService.RestoreTask(ctx, other.ID, task.ID), service.ErrTaskNotFound)
+
+	// Restoring the task brings its category back with it
+	require.NoError(t, trashService.RestoreTask(ctx, owner.ID, task.ID))
+	restored, err := taskService.GetTaskByID(ctx, owner.ID, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, restored.CategoryID)
+	_, err = categoryService.GetCategoryByID(ctx, owner.ID, category.ID)
+	require.NoError(t, err)
+
+	// Only deleted items can be purged, and purging is final
+	assert.ErrorIs(t, trashService.PurgeTask(ctx, owner.ID, task.ID), service.ErrTaskNotFound)
+	require.NoError(t, taskService.DeleteTask(ctx, owner.ID, task.ID, nil))
+	require.NoError(t, trashService.PurgeTask(ctx, owner.ID, task.ID))
+	assert.ErrorIs(t, trashService.RestoreTask(ctx, owner.ID, task.ID), service.ErrTaskNotFound)
+
+	// Items past the retention period are purged by PurgeExpired
+	expired := &model.Task{Title: "Old", UserID: owner.ID}
+	require.NoError(t, taskService.CreateTask(ctx, expired))
+	require.NoError(t, db.Model(&model.Task{}).Where("id = ?", expired.ID).Update("deleted_at", time.Now().Add(-2*time.Hour)).Error)
+	purged, err := trashService.PurgeExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	// Purging a series head hands the series to its next occurrence
+	head := &model.Task{Title: "Daily", UserID: owner.ID}
+	require.NoError(t, taskRepo.Create(ctx, head))
+	second := &model.Task{Title: "Daily", UserID: owner.ID, SeriesID: &head.ID, Occurrence: 2}
+	require.NoError(t, taskRepo.Create(ctx, second))
+	third := &model.Task{Title: "Daily", UserID: owner.ID, SeriesID: &head.ID, Occurrence: 3}
+	require.NoError(t, taskRepo.Create(ctx, third))
+	require.NoError(t, taskService.DeleteTask(ctx, owner.ID, head.ID, nil))
+	require.NoError(t, trashService.PurgeTask(ctx, owner.ID, head.ID))
+	second, err = taskRepo.GetByID(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Nil(t, second.SeriesID)
+	third, err = taskRepo.GetByID(ctx, third.ID)
+	require.NoError(t, err)
+	require.NotNil(t, third.SeriesID)
+	assert.Equal(t, second.ID, *third.SeriesID)
+}
+
+func TestTaskService_BulkTasks(t *testing.T) {
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("Test database not available")
+	}
+	userRepo := repository.NewUserRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	taskService := newTestTaskService(taskRepo, db)
+
+	owner := &model.User{Username: "owner", Email: "owner@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, owner))
+	other := &model.User{Username: "other", Email: "other@example.com", Password: "password123"}
+	require.NoError(t, userRepo.Create(ctx, other))
+
+	results, err := taskService.BulkCreateTasks(ctx, owner.ID, []*model.Task{{Title: "A"}, {Title: "B"}}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		require.NoError(t, result.Err)
+	}
+	a, b := results[0].ID, results[1].ID
+
+	foreign := &model.Task{Title: "Foreign", UserID: other.ID}
+	require.NoError(t, taskService.CreateTask(ctx, foreign))
+
+	// An atomic update with an inaccessible task changes nothing
+	setHigh := func(task *model.Task) { task.Priority = model.TaskPriorityHigh }
+	results, err = taskService.BulkUpdateTasks(ctx, owner.ID, service.BulkTarget{IDs: []uuid.UUID{a, foreign.ID}}, setHigh, true)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, service.ErrBulkAborted)
+	assert.ErrorIs(t, results[1].Err, service.ErrTaskNotFound)
+	task, err := taskService.GetTaskByID(ctx, owner.ID, a)
+	require.NoError(t, err)
+	assert.Equal(t, model.TaskPriorityMedium, task.Priority)
+
+	// In best-effort mode the valid items are still applied
+	results, err = taskService.BulkUpdateTasks(ctx, owner.ID, service.BulkTarget{IDs: []uuid.UUID{a, foreign.ID}}, setHigh, false)
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, service.ErrTaskNotFound)
+	task, err = taskService.GetTaskByID(ctx, owner.ID, a)
+	require.NoError(t, err)
+	assert.Equal(t, model.TaskPriorityHigh, task.Priority)
+
+	// Status changes follow the transition rules and are recorded
+	complete := func(task *model.Task) { task.Status = model.TaskStatusCompleted }
+	filter := model.TaskFilter{Priorities: []model.TaskPriority{model.TaskPriorityHigh}}
+	results, err = taskService.BulkUpdateTasks(ctx, owner.ID, service.BulkTarget{Filter: &filter}, complete, true)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].Err)
+	history, err := taskService.GetStatusHistory(ctx, owner.ID, a)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	// Deleting by filter only reaches the user's own tasks
+	results, err = taskService.BulkDeleteTasks(ctx, owner.ID, service.BulkTarget{Filter: &model.TaskFilter{}}, true)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	_, err = taskService.GetTaskByID(ctx, owner.ID, b)
+	assert.ErrorIs(t, err, service.ErrTaskNotFound)
+	_, err = taskService.GetTaskByID(ctx, other.ID, foreign.ID)
+	assert.NoError(t, err)
+
+	_, err = taskService.BulkDeleteTasks(ctx, owner.ID, service.BulkTarget{}, true)
+	assert.ErrorIs(t, err, service.ErrInvalidBulk)
 }
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"Arise-test/internal/storage"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is an in-memory stand-in for an S3-compatible service that checks
+// requests carry a Signature Version 4 authorization
+func fakeS3(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=access/") ||
+			!strings.Contains(auth, "/us-east-1/s3/aws4_request") ||
+			r.Header.Get("X-Amz-Date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			objects[r.URL.Path] = data
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func testStorage(t *testing.T, store storage.Storage) {
+	err := store.Put(ctx, "tasks/1/a", strings.NewReader("hello"), 5, "text/plain")
+	require.NoError(t, err)
+
+	content, err := store.Get(ctx, "tasks/1/a")
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	content.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, store.Delete(ctx, "tasks/1/a"))
+	_, err = store.Get(ctx, "tasks/1/a")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// Deleting a missing object is not an error
+	assert.NoError(t, store.Delete(ctx, "tasks/1/a"))
+}
+
+func TestLocalStorage(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	testStorage(t, store)
+
+	// Keys cannot escape the root directory
+	err = store.Put(ctx, "../outside", strings.NewReader("x"), 1, "text/plain")
+	assert.Error(t, err)
+	// A short body is not stored
+	err = store.Put(ctx, "tasks/1/b", strings.NewReader("abc"), 5, "text/plain")
+	assert.Error(t, err)
+	_, err = store.Get(ctx, "tasks/1/b")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestS3Storage(t *testing.T) {
+	server := fakeS3(t)
+	defer server.Close()
+
+	store, err := storage.NewS3Storage(storage.S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "attachments",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	require.NoError(t, err)
+
+	testStorage(t, store)
+
+	// Requests are bound to the context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = store.Put(cancelled, "tasks/1/c", strings.NewReader("x"), 1, "text/plain")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = storage.NewS3Storage(storage.S3Config{Endpoint: server.URL})
+	assert.Error(t, err)
+}